@@ -44,12 +44,12 @@ export default function Friends() {
         }
     }
 
-    const handleBlock = async (requestId) => {
+    const handleDecline = async (requestId) => {
         try {
-            await friendsAPI.block(requestId)
+            await friendsAPI.decline(requestId)
             setPending(pending.filter(p => p.id !== requestId))
         } catch (err) {
-            console.error('Failed to block user')
+            console.error('Failed to decline request')
         }
     }
 
@@ -207,7 +207,7 @@ export default function Friends() {
                                                     </motion.button>
                                                     <button
                                                         className="btn btn-ghost btn-sm"
-                                                        onClick={() => handleBlock(request.id)}
+                                                        onClick={() => handleDecline(request.id)}
                                                     >
                                                         Decline
                                                     </button>
@@ -61,6 +61,8 @@ export default function Messages() {
         try {
             const res = await messagesAPI.getMessages(convId)
             setMessages(res.data || [])
+            await messagesAPI.markConversationRead(convId)
+            setConversations(convs => convs.map(c => c.id === convId ? { ...c, unread_count: 0 } : c))
         } catch (err) {
             console.error('Failed to load messages')
         }
@@ -79,6 +81,15 @@ export default function Messages() {
         }
     }
 
+    const conversationName = (conv) => {
+        if (!conv.is_group) {
+            return conv.participant?.full_name || conv.participant?.username || 'User'
+        }
+        if (conv.title) return conv.title
+        const others = (conv.members || []).filter(m => m.user_id !== user?.id)
+        return others.map(m => m.user?.username).filter(Boolean).join(', ') || 'Group'
+    }
+
     const formatTime = (dateStr) => {
         const date = new Date(dateStr)
         return date.toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' })
@@ -133,17 +144,20 @@ export default function Messages() {
                                         ) : conv.participant?.avatar_url ? (
                                             <img src={conv.participant.avatar_url} alt="" />
                                         ) : (
-                                            conv.participant?.username?.charAt(0).toUpperCase() || 'U'
+                                            (conv.is_group ? conversationName(conv) : conv.participant?.username)?.charAt(0).toUpperCase() || 'U'
                                         )}
                                     </div>
                                     <div className="conversation-info">
                                         <span className="conversation-name">
-                                            {conv.participant?.full_name || conv.participant?.username || 'User'}
+                                            {conversationName(conv)}
                                         </span>
                                         <span className="conversation-preview">
                                             {conv.last_message?.body || 'No messages'}
                                         </span>
                                     </div>
+                                    {conv.unread_count > 0 && (
+                                        <span className="badge badge-primary conversation-unread">{conv.unread_count}</span>
+                                    )}
                                 </motion.div>
                             ))
                         )}
@@ -185,12 +199,12 @@ export default function Messages() {
                                     ) : activeConversation.participant?.avatar_url ? (
                                         <img src={activeConversation.participant.avatar_url} alt="" />
                                     ) : (
-                                        activeConversation.participant?.username?.charAt(0).toUpperCase() || 'U'
+                                        (activeConversation.is_group ? conversationName(activeConversation) : activeConversation.participant?.username)?.charAt(0).toUpperCase() || 'U'
                                     )}
                                 </div>
                                 <div className="chat-header-info">
-                                    <h3>{activeConversation.participant?.full_name || activeConversation.participant?.username || 'User'}</h3>
-                                    <span>Online</span>
+                                    <h3>{conversationName(activeConversation)}</h3>
+                                    <span>{activeConversation.is_group ? `${activeConversation.members?.length || 0} members` : 'Online'}</span>
                                 </div>
                             </div>
 
@@ -199,12 +213,15 @@ export default function Messages() {
                                     {messages.map((msg, idx) => (
                                         <motion.div
                                             key={msg.id}
-                                            className={`message ${msg.user_id === user?.id ? 'own' : ''}`}
+                                            className={`message ${msg.type === 'system' ? 'system' : msg.user_id === user?.id ? 'own' : ''}`}
                                             initial={{ opacity: 0, y: 10 }}
                                             animate={{ opacity: 1, y: 0 }}
                                             transition={{ delay: idx * 0.02 }}
                                         >
                                             <div className="message-bubble">
+                                                {activeConversation.is_group && msg.type !== 'system' && msg.user_id !== user?.id && (
+                                                    <span className="message-author">{msg.author?.username}</span>
+                                                )}
                                                 <p>{msg.body}</p>
                                                 <span className="message-time">{formatTime(msg.created_at)}</span>
                                             </div>
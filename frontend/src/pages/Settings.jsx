@@ -30,6 +30,7 @@ export default function Settings() {
     const [privacySettings, setPrivacySettings] = useState({
         show_last_seen: 'all',
         allow_messages_from: 'all',
+        read_receipts: true,
     })
     const [selectedEmoji, setSelectedEmoji] = useState('')
     const [loading, setLoading] = useState(false)
@@ -53,6 +54,7 @@ export default function Settings() {
             setPrivacySettings({
                 show_last_seen: showLastSeen,
                 allow_messages_from: allowMessagesFrom,
+                read_receipts: user.read_receipts ?? true,
             })
         }
     }, [user])
@@ -233,6 +235,17 @@ export default function Settings() {
                         <option value="friends">Friends Only</option>
                     </select>
                 </div>
+                <div className="form-group">
+                    <label>
+                        <input
+                            type="checkbox"
+                            name="read_receipts"
+                            checked={privacySettings.read_receipts}
+                            onChange={e => setPrivacySettings({ ...privacySettings, read_receipts: e.target.checked })}
+                        />
+                        {' '}Send Read Receipts
+                    </label>
+                </div>
                 <button onClick={handlePrivacySave} disabled={loading}>
                     Save Privacy Settings
                 </button>
@@ -80,6 +80,17 @@ export const messagesAPI = {
   getConversations: () => api.get('/conversations'),
   startConversation: (participantId) => api.post('/conversations', { participant_id: participantId }),
   createConversation: (participantId) => api.post('/conversations', { participant_id: participantId }),
+  createGroupConversation: (participantIds, title) => api.post('/conversations', { participant_ids: participantIds, title }),
+  getConversation: (conversationId) => api.get(`/conversations/${conversationId}`),
+  renameConversation: (conversationId, title) => api.put(`/conversations/${conversationId}`, { title }),
+  addConversationMembers: (conversationId, userIds) => api.post(`/conversations/${conversationId}/members`, { user_ids: userIds }),
+  removeConversationMember: (conversationId, userId) => api.delete(`/conversations/${conversationId}/members/${userId}`),
+  setConversationMemberRole: (conversationId, userId, role) => api.put(`/conversations/${conversationId}/members/${userId}`, { role }),
+  leaveConversation: (conversationId) => api.post(`/conversations/${conversationId}/leave`),
+  markConversationRead: (conversationId, messageId) => api.post(`/conversations/${conversationId}/read`, messageId ? { message_id: messageId } : undefined),
+  getUnreadSummary: () => api.get('/conversations/unread'),
+  muteConversation: (conversationId) => api.put(`/conversations/${conversationId}/mute`),
+  unmuteConversation: (conversationId) => api.delete(`/conversations/${conversationId}/mute`),
   getMessages: (conversationId) => api.get(`/conversations/${conversationId}/messages`),
   sendMessage: (conversationId, body) => api.post(`/conversations/${conversationId}/messages`, { body }),
 }
@@ -136,7 +147,8 @@ export const friendsAPI = {
   getPending: () => api.get('/friends/requests'),
   acceptRequest: (requestId) => api.put(`/friends/${requestId}`, {}),
   accept: (requestId) => api.put(`/friends/${requestId}`, {}),
-  block: (requestId) => api.delete(`/friends/${requestId}`),
+  decline: (requestId) => api.delete(`/friends/${requestId}`),
+  block: (requestId) => api.put(`/friends/${requestId}/block`, {}),
 }
 
 export default api
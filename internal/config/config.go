@@ -8,34 +8,56 @@ import (
 )
 
 type Config struct {
-	DatabasePath    string
-	ServerPort      string
-	JWTSecret       string
-	SessionDuration time.Duration
-	MaxUploadSize   int64
-	RateLimitPerMin int
-	CleanupInterval time.Duration
-	MongoDBURI      string
-	FirebaseKeyPath string
-	InitialAdmins   []string
-	UploadDir       string
-	FrontendDir     string
+	DatabasePath      string
+	ServerPort        string
+	JWTSecret         string
+	SessionDuration   time.Duration
+	MaxUploadSize     int64
+	RateLimitPerMin   int
+	CleanupInterval   time.Duration
+	SchedulerInterval time.Duration
+	StoryTTL          time.Duration
+	MongoDBURI        string
+	FirebaseKeyPath   string
+	InitialAdmins     []string
+	UploadDir         string
+	FrontendDir       string
+	Reactions         []string
+	// LinkPreviewAllowPrivate lets the preview fetcher reach private and
+	// loopback addresses. Only meant for local testing.
+	LinkPreviewAllowPrivate bool
+	LinkPreviewTimeout      time.Duration
+	// WebSocketPingInterval is how often sockets are pinged. A client that
+	// sends nothing for two intervals is disconnected.
+	WebSocketPingInterval time.Duration
+	// WebSocketResumeWindow is how long events are kept for clients that
+	// reconnect with last_event_id.
+	WebSocketResumeWindow time.Duration
 }
 
 func Load() *Config {
 	return &Config{
-		DatabasePath:    getEnv("DB_PATH", "socialnet.db"),
-		ServerPort:      getEnv("SERVER_PORT", "8080"),
-		JWTSecret:       getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
-		SessionDuration: getDuration("SESSION_DURATION", 24*time.Hour),
-		MaxUploadSize:   getInt64("MAX_UPLOAD_SIZE", 10*1024*1024),
-		RateLimitPerMin: getInt("RATE_LIMIT_PER_MIN", 60),
-		CleanupInterval: getDuration("CLEANUP_INTERVAL", 1*time.Hour),
-		MongoDBURI:      getEnv("MONGODB_URI", ""),
-		FirebaseKeyPath: getEnv("FIREBASE_KEY_PATH", ""),
-		InitialAdmins:   getStringSlice("INITIAL_ADMINS", []string{}),
-		UploadDir:       getEnv("UPLOAD_DIR", "./uploads"),
-		FrontendDir:     getEnv("FRONTEND_DIR", ""),
+		DatabasePath:      getEnv("DB_PATH", "socialnet.db"),
+		ServerPort:        getEnv("SERVER_PORT", "8080"),
+		JWTSecret:         getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
+		SessionDuration:   getDuration("SESSION_DURATION", 24*time.Hour),
+		MaxUploadSize:     getInt64("MAX_UPLOAD_SIZE", 10*1024*1024),
+		RateLimitPerMin:   getInt("RATE_LIMIT_PER_MIN", 60),
+		CleanupInterval:   getDuration("CLEANUP_INTERVAL", 1*time.Hour),
+		SchedulerInterval: getDuration("SCHEDULER_INTERVAL", 30*time.Second),
+		StoryTTL:          getDuration("STORY_TTL", 24*time.Hour),
+		MongoDBURI:        getEnv("MONGODB_URI", ""),
+		FirebaseKeyPath:   getEnv("FIREBASE_KEY_PATH", ""),
+		InitialAdmins:     getStringSlice("INITIAL_ADMINS", []string{}),
+		UploadDir:         getEnv("UPLOAD_DIR", "./uploads"),
+		FrontendDir:       getEnv("FRONTEND_DIR", ""),
+		Reactions:         getStringSlice("REACTIONS", []string{}),
+
+		LinkPreviewAllowPrivate: getBool("LINK_PREVIEW_ALLOW_PRIVATE", false),
+		LinkPreviewTimeout:      getDuration("LINK_PREVIEW_TIMEOUT", 5*time.Second),
+
+		WebSocketPingInterval: getDuration("WS_PING_INTERVAL", 25*time.Second),
+		WebSocketResumeWindow: getDuration("WS_RESUME_WINDOW", 5*time.Minute),
 	}
 }
 
@@ -64,6 +86,15 @@ func getInt64(key string, defaultValue int64) int64 {
 	return defaultValue
 }
 
+func getBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolVal, err := strconv.ParseBool(value); err == nil {
+			return boolVal
+		}
+	}
+	return defaultValue
+}
+
 func getDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
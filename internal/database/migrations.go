@@ -2,6 +2,8 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
 )
 
 func runMigrations(db *sql.DB) error {
@@ -16,6 +18,38 @@ func runMigrations(db *sql.DB) error {
 		`ALTER TABLE group_members ADD COLUMN role TEXT DEFAULT 'member'`,
 		`ALTER TABLE group_posts ADD COLUMN media_url TEXT`,
 		`ALTER TABLE posts ADD COLUMN media_url TEXT`,
+		`ALTER TABLE posts ADD COLUMN repost_of_id INTEGER`,
+		`ALTER TABLE posts ADD COLUMN quote_of_id INTEGER`,
+		`ALTER TABLE posts ADD COLUMN edited_at TIMESTAMP`,
+		`ALTER TABLE posts ADD COLUMN status TEXT DEFAULT 'published'`,
+		`ALTER TABLE posts ADD COLUMN publish_at TIMESTAMP`,
+		`ALTER TABLE reports ADD COLUMN revision_id INTEGER`,
+		`ALTER TABLE comments ADD COLUMN parent_id INTEGER`,
+		`ALTER TABLE comments ADD COLUMN depth INTEGER DEFAULT 0`,
+		`ALTER TABLE comments ADD COLUMN edited_at TIMESTAMP`,
+		`ALTER TABLE comments ADD COLUMN deleted_at TIMESTAMP`,
+		`ALTER TABLE messages ADD COLUMN story_id INTEGER`,
+		`ALTER TABLE users ADD COLUMN sensitive_content TEXT DEFAULT 'hide'`,
+		`ALTER TABLE posts ADD COLUMN content_warning TEXT`,
+		`ALTER TABLE posts ADD COLUMN sensitive BOOLEAN DEFAULT FALSE`,
+		`ALTER TABLE posts ADD COLUMN flags_locked BOOLEAN DEFAULT FALSE`,
+		`ALTER TABLE group_posts ADD COLUMN content_warning TEXT`,
+		`ALTER TABLE group_posts ADD COLUMN sensitive BOOLEAN DEFAULT FALSE`,
+		`ALTER TABLE notifications ADD COLUMN muted BOOLEAN DEFAULT FALSE`,
+		`ALTER TABLE users ADD COLUMN private_account BOOLEAN DEFAULT TRUE`,
+		`ALTER TABLE friendships ADD COLUMN blocked_by INTEGER`,
+		`ALTER TABLE conversation_members ADD COLUMN status TEXT DEFAULT 'accepted'`,
+		`ALTER TABLE conversations ADD COLUMN title TEXT`,
+		`ALTER TABLE conversations ADD COLUMN is_group BOOLEAN DEFAULT FALSE`,
+		`ALTER TABLE conversations ADD COLUMN created_by INTEGER`,
+		`ALTER TABLE conversation_members ADD COLUMN role TEXT DEFAULT 'member'`,
+		`ALTER TABLE conversation_members ADD COLUMN added_by INTEGER`,
+		`ALTER TABLE messages ADD COLUMN type TEXT DEFAULT 'text'`,
+		`ALTER TABLE conversation_members ADD COLUMN last_read_message_id INTEGER`,
+		`ALTER TABLE users ADD COLUMN read_receipts BOOLEAN DEFAULT TRUE`,
+		`ALTER TABLE conversation_members ADD COLUMN muted BOOLEAN DEFAULT FALSE`,
+		`ALTER TABLE notifications ADD COLUMN actor_id INTEGER`,
+		`ALTER TABLE notifications ADD COLUMN count INTEGER DEFAULT 1`,
 	}
 
 	for _, query := range alterQueries {
@@ -37,6 +71,9 @@ func runMigrations(db *sql.DB) error {
 			last_seen TIMESTAMP,
 			show_last_seen TEXT DEFAULT 'all',
 			allow_messages_from TEXT DEFAULT 'all',
+			sensitive_content TEXT DEFAULT 'hide',
+			private_account BOOLEAN DEFAULT TRUE,
+			read_receipts BOOLEAN DEFAULT TRUE,
 			firebase_uid TEXT,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)`,
@@ -46,28 +83,56 @@ func runMigrations(db *sql.DB) error {
 			user_id INTEGER NOT NULL,
 			content TEXT NOT NULL,
 			media_url TEXT,
+			repost_of_id INTEGER,
+			quote_of_id INTEGER,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			edited_at TIMESTAMP,
+			status TEXT CHECK(status IN ('draft', 'scheduled', 'published')) DEFAULT 'published',
+			publish_at TIMESTAMP,
+			content_warning TEXT,
+			sensitive BOOLEAN DEFAULT FALSE,
+			flags_locked BOOLEAN DEFAULT FALSE,
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 		)`,
 
+		`CREATE TABLE IF NOT EXISTS post_revisions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			post_id INTEGER NOT NULL,
+			revision INTEGER NOT NULL,
+			content TEXT NOT NULL,
+			media_url TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(post_id, revision),
+			FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+		)`,
+
+		`INSERT INTO post_revisions (post_id, revision, content, media_url, created_at)
+			SELECT id, 1, content, media_url, updated_at FROM posts p
+			WHERE repost_of_id IS NULL AND NOT EXISTS(SELECT 1 FROM post_revisions pr WHERE pr.post_id = p.id)`,
+
 		`CREATE TABLE IF NOT EXISTS comments (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			post_id INTEGER NOT NULL,
 			user_id INTEGER NOT NULL,
+			parent_id INTEGER,
+			depth INTEGER DEFAULT 0,
 			content TEXT NOT NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			edited_at TIMESTAMP,
+			deleted_at TIMESTAMP,
 			FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 		)`,
 
-		`CREATE TABLE IF NOT EXISTS likes (
+		`CREATE TABLE IF NOT EXISTS reactions (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			post_id INTEGER NOT NULL,
+			target_type TEXT CHECK(target_type IN ('post', 'comment', 'message')) NOT NULL,
+			target_id INTEGER NOT NULL,
 			user_id INTEGER NOT NULL,
+			reaction TEXT NOT NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			UNIQUE(post_id, user_id),
-			FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+			UNIQUE(target_type, target_id, user_id),
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 		)`,
 
@@ -76,6 +141,7 @@ func runMigrations(db *sql.DB) error {
 			requester_id INTEGER NOT NULL,
 			addressee_id INTEGER NOT NULL,
 			status TEXT CHECK(status IN ('pending', 'accepted', 'blocked')) DEFAULT 'pending',
+			blocked_by INTEGER,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			UNIQUE(requester_id, addressee_id),
@@ -83,8 +149,22 @@ func runMigrations(db *sql.DB) error {
 			FOREIGN KEY (addressee_id) REFERENCES users(id) ON DELETE CASCADE
 		)`,
 
+		`CREATE TABLE IF NOT EXISTS follows (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			follower_id INTEGER NOT NULL,
+			followee_id INTEGER NOT NULL,
+			status TEXT CHECK(status IN ('pending', 'accepted')) DEFAULT 'pending',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(follower_id, followee_id),
+			FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (followee_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+
 		`CREATE TABLE IF NOT EXISTS conversations (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			title TEXT,
+			is_group BOOLEAN DEFAULT FALSE,
+			created_by INTEGER,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)`,
 
@@ -92,6 +172,11 @@ func runMigrations(db *sql.DB) error {
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			conversation_id INTEGER NOT NULL,
 			user_id INTEGER NOT NULL,
+			status TEXT DEFAULT 'accepted',
+			role TEXT DEFAULT 'member',
+			added_by INTEGER,
+			last_read_message_id INTEGER,
+			muted BOOLEAN DEFAULT FALSE,
 			joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			UNIQUE(conversation_id, user_id),
 			FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
@@ -102,7 +187,9 @@ func runMigrations(db *sql.DB) error {
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			conversation_id INTEGER NOT NULL,
 			user_id INTEGER NOT NULL,
+			type TEXT DEFAULT 'text',
 			body TEXT NOT NULL,
+			story_id INTEGER,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			read_at TIMESTAMP,
 			FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
@@ -136,6 +223,8 @@ func runMigrations(db *sql.DB) error {
 			user_id INTEGER NOT NULL,
 			content TEXT NOT NULL,
 			media_url TEXT,
+			content_warning TEXT,
+			sensitive BOOLEAN DEFAULT FALSE,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE,
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
@@ -148,6 +237,9 @@ func runMigrations(db *sql.DB) error {
 			target_id INTEGER,
 			message TEXT NOT NULL,
 			read BOOLEAN DEFAULT FALSE,
+			muted BOOLEAN DEFAULT FALSE,
+			actor_id INTEGER,
+			count INTEGER DEFAULT 1,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 		)`,
@@ -159,6 +251,7 @@ func runMigrations(db *sql.DB) error {
 			target_id INTEGER NOT NULL,
 			reason TEXT NOT NULL,
 			status TEXT CHECK(status IN ('pending', 'reviewed', 'resolved')) DEFAULT 'pending',
+			revision_id INTEGER,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE CASCADE
 		)`,
@@ -171,16 +264,319 @@ func runMigrations(db *sql.DB) error {
 			FOREIGN KEY (admin_id) REFERENCES users(id) ON DELETE CASCADE
 		)`,
 
+		`CREATE TABLE IF NOT EXISTS media (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			url TEXT UNIQUE NOT NULL,
+			content_type TEXT NOT NULL,
+			size INTEGER NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS attachments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			target_type TEXT CHECK(target_type IN ('post', 'group_post')) NOT NULL,
+			target_id INTEGER NOT NULL,
+			media_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			alt_text TEXT,
+			UNIQUE(target_type, target_id, position),
+			FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS link_previews (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			url TEXT UNIQUE NOT NULL,
+			status TEXT CHECK(status IN ('pending', 'ready', 'failed')) DEFAULT 'pending',
+			title TEXT,
+			description TEXT,
+			image_url TEXT,
+			site_name TEXT,
+			fetched_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS bookmark_collections (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			UNIQUE(user_id, name)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS bookmarks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			post_id INTEGER NOT NULL,
+			collection_id INTEGER,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+			FOREIGN KEY (collection_id) REFERENCES bookmark_collections(id) ON DELETE SET NULL,
+			UNIQUE(user_id, post_id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS stories (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			content TEXT,
+			media_url TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			expires_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS muted_words (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			phrase TEXT NOT NULL,
+			whole_word BOOLEAN DEFAULT TRUE,
+			expires_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			UNIQUE(user_id, phrase)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS user_mutes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			muted_user_id INTEGER NOT NULL,
+			expires_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (muted_user_id) REFERENCES users(id) ON DELETE CASCADE,
+			UNIQUE(user_id, muted_user_id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS friend_suggestions (
+			user_id INTEGER NOT NULL,
+			suggested_user_id INTEGER NOT NULL,
+			mutual_friends INTEGER NOT NULL DEFAULT 0,
+			shared_groups INTEGER NOT NULL DEFAULT 0,
+			shared_emoji BOOLEAN NOT NULL DEFAULT FALSE,
+			score INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (user_id, suggested_user_id),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (suggested_user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS suggestion_dismissals (
+			user_id INTEGER NOT NULL,
+			dismissed_user_id INTEGER NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, dismissed_user_id),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (dismissed_user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS suggestion_refresh (
+			user_id INTEGER PRIMARY KEY,
+			stale BOOLEAN NOT NULL DEFAULT TRUE,
+			refreshed_at TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS story_views (
+			story_id INTEGER NOT NULL,
+			viewer_id INTEGER NOT NULL,
+			viewed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (story_id, viewer_id),
+			FOREIGN KEY (story_id) REFERENCES stories(id) ON DELETE CASCADE,
+			FOREIGN KEY (viewer_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS polls (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			post_id INTEGER UNIQUE NOT NULL,
+			multiple BOOLEAN DEFAULT FALSE,
+			anonymous BOOLEAN DEFAULT FALSE,
+			closes_at TIMESTAMP,
+			close_notified BOOLEAN DEFAULT FALSE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS poll_options (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			poll_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			text TEXT NOT NULL,
+			FOREIGN KEY (poll_id) REFERENCES polls(id) ON DELETE CASCADE
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS poll_votes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			poll_id INTEGER NOT NULL,
+			option_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(option_id, user_id),
+			FOREIGN KEY (poll_id) REFERENCES polls(id) ON DELETE CASCADE,
+			FOREIGN KEY (option_id) REFERENCES poll_options(id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS mentions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			author_id INTEGER NOT NULL,
+			target_type TEXT CHECK(target_type IN ('post', 'comment', 'group_post', 'message')) NOT NULL,
+			target_id INTEGER NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(target_type, target_id, user_id),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+
+		`CREATE TRIGGER IF NOT EXISTS trg_posts_delete_mentions AFTER DELETE ON posts BEGIN
+			DELETE FROM mentions WHERE target_type = 'post' AND target_id = old.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_comments_delete_mentions AFTER DELETE ON comments BEGIN
+			DELETE FROM mentions WHERE target_type = 'comment' AND target_id = old.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_group_posts_delete_mentions AFTER DELETE ON group_posts BEGIN
+			DELETE FROM mentions WHERE target_type = 'group_post' AND target_id = old.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_messages_delete_mentions AFTER DELETE ON messages BEGIN
+			DELETE FROM mentions WHERE target_type = 'message' AND target_id = old.id;
+		END`,
+
+		`CREATE TRIGGER IF NOT EXISTS trg_posts_delete_revisions AFTER DELETE ON posts BEGIN
+			DELETE FROM post_revisions WHERE post_id = old.id
+				AND id NOT IN (SELECT revision_id FROM reports WHERE revision_id IS NOT NULL);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_posts_delete_attachments AFTER DELETE ON posts BEGIN
+			DELETE FROM attachments WHERE target_type = 'post' AND target_id = old.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_group_posts_delete_attachments AFTER DELETE ON group_posts BEGIN
+			DELETE FROM attachments WHERE target_type = 'group_post' AND target_id = old.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_posts_delete_polls AFTER DELETE ON posts BEGIN
+			DELETE FROM poll_votes WHERE poll_id IN (SELECT id FROM polls WHERE post_id = old.id);
+			DELETE FROM poll_options WHERE poll_id IN (SELECT id FROM polls WHERE post_id = old.id);
+			DELETE FROM polls WHERE post_id = old.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_posts_delete_bookmarks AFTER DELETE ON posts BEGIN
+			DELETE FROM bookmarks WHERE post_id = old.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_bookmark_collections_delete AFTER DELETE ON bookmark_collections BEGIN
+			UPDATE bookmarks SET collection_id = NULL WHERE collection_id = old.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_users_delete_follows AFTER DELETE ON users BEGIN
+			DELETE FROM follows WHERE follower_id = old.id OR followee_id = old.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_users_delete_mutes AFTER DELETE ON users BEGIN
+			DELETE FROM user_mutes WHERE user_id = old.id OR muted_user_id = old.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_users_delete_suggestions AFTER DELETE ON users BEGIN
+			DELETE FROM friend_suggestions WHERE user_id = old.id OR suggested_user_id = old.id;
+			DELETE FROM suggestion_dismissals WHERE user_id = old.id OR dismissed_user_id = old.id;
+			DELETE FROM suggestion_refresh WHERE user_id = old.id;
+		END`,
+
+		// Friend suggestions are recomputed per user by a worker. These triggers
+		// mark whoever a change can affect: both sides of a friendship and their
+		// friends, everyone in a group someone joins or leaves, and anyone
+		// currently being suggested a user whose emoji avatar changed.
+		`CREATE TRIGGER IF NOT EXISTS trg_friendships_insert_suggestions AFTER INSERT ON friendships BEGIN
+			INSERT INTO suggestion_refresh (user_id)
+			SELECT id FROM users WHERE id IN (new.requester_id, new.addressee_id)
+			ON CONFLICT(user_id) DO UPDATE SET stale = TRUE;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_friendships_update_suggestions AFTER UPDATE OF status ON friendships BEGIN
+			INSERT INTO suggestion_refresh (user_id)
+			SELECT id FROM users WHERE id IN (new.requester_id, new.addressee_id)
+			UNION SELECT addressee_id FROM friendships
+				WHERE requester_id IN (new.requester_id, new.addressee_id) AND status = 'accepted'
+			UNION SELECT requester_id FROM friendships
+				WHERE addressee_id IN (new.requester_id, new.addressee_id) AND status = 'accepted'
+			ON CONFLICT(user_id) DO UPDATE SET stale = TRUE;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_friendships_delete_suggestions AFTER DELETE ON friendships BEGIN
+			INSERT INTO suggestion_refresh (user_id)
+			SELECT id FROM users WHERE id IN (old.requester_id, old.addressee_id)
+			UNION SELECT addressee_id FROM friendships
+				WHERE requester_id IN (old.requester_id, old.addressee_id) AND status = 'accepted'
+				AND old.status = 'accepted'
+			UNION SELECT requester_id FROM friendships
+				WHERE addressee_id IN (old.requester_id, old.addressee_id) AND status = 'accepted'
+				AND old.status = 'accepted'
+			ON CONFLICT(user_id) DO UPDATE SET stale = TRUE;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_group_members_insert_suggestions AFTER INSERT ON group_members BEGIN
+			INSERT INTO suggestion_refresh (user_id)
+			SELECT user_id FROM group_members WHERE group_id = new.group_id
+			ON CONFLICT(user_id) DO UPDATE SET stale = TRUE;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_group_members_delete_suggestions AFTER DELETE ON group_members BEGIN
+			INSERT INTO suggestion_refresh (user_id)
+			SELECT id FROM users WHERE id = old.user_id
+			UNION SELECT user_id FROM group_members WHERE group_id = old.group_id
+			ON CONFLICT(user_id) DO UPDATE SET stale = TRUE;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_users_emoji_suggestions AFTER UPDATE OF emoji_avatar ON users
+			WHEN new.emoji_avatar IS NOT old.emoji_avatar BEGIN
+			INSERT INTO suggestion_refresh (user_id)
+			SELECT id FROM users WHERE id = new.id
+			UNION SELECT user_id FROM friend_suggestions WHERE suggested_user_id = new.id
+			ON CONFLICT(user_id) DO UPDATE SET stale = TRUE;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_stories_delete_views AFTER DELETE ON stories BEGIN
+			DELETE FROM story_views WHERE story_id = old.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_posts_delete_reactions AFTER DELETE ON posts BEGIN
+			DELETE FROM reactions WHERE target_type = 'post' AND target_id = old.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_comments_delete_reactions AFTER DELETE ON comments BEGIN
+			DELETE FROM reactions WHERE target_type = 'comment' AND target_id = old.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_messages_delete_reactions AFTER DELETE ON messages BEGIN
+			DELETE FROM reactions WHERE target_type = 'message' AND target_id = old.id;
+		END`,
+
 		`CREATE INDEX IF NOT EXISTS idx_posts_user_id ON posts(user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_stories_user ON stories(user_id, expires_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_stories_expires ON stories(expires_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_muted_words_expires ON muted_words(expires_at) WHERE expires_at IS NOT NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_user_mutes_expires ON user_mutes(expires_at) WHERE expires_at IS NOT NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_friend_suggestions_suggested ON friend_suggestions(suggested_user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_suggestion_refresh_stale ON suggestion_refresh(refreshed_at) WHERE stale = TRUE`,
+		`CREATE INDEX IF NOT EXISTS idx_group_members_user ON group_members(user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_bookmarks_user ON bookmarks(user_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_bookmarks_post ON bookmarks(post_id)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_posts_repost ON posts(repost_of_id, user_id) WHERE repost_of_id IS NOT NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_posts_quote ON posts(quote_of_id) WHERE quote_of_id IS NOT NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_posts_scheduled ON posts(publish_at) WHERE status = 'scheduled'`,
 		`CREATE INDEX IF NOT EXISTS idx_comments_post_id ON comments(post_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_likes_post_id ON likes(post_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments(parent_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_reactions_user ON reactions(user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_media_user ON media(user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_poll_options_poll ON poll_options(poll_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_poll_votes_poll ON poll_votes(poll_id, user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_polls_closing ON polls(closes_at) WHERE close_notified = FALSE`,
 		`CREATE INDEX IF NOT EXISTS idx_friendships_requester ON friendships(requester_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_friendships_addressee ON friendships(addressee_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_follows_follower ON follows(follower_id, status)`,
+		`CREATE INDEX IF NOT EXISTS idx_follows_followee ON follows(followee_id, status)`,
 		`CREATE INDEX IF NOT EXISTS idx_messages_conversation ON messages(conversation_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_reports_status ON reports(status)`,
 		`CREATE INDEX IF NOT EXISTS idx_users_emoji ON users(emoji_avatar)`,
 		`CREATE INDEX IF NOT EXISTS idx_users_firebase ON users(firebase_uid)`,
+		`CREATE INDEX IF NOT EXISTS idx_mentions_user ON mentions(user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_conversation_members_user ON conversation_members(user_id)`,
+
+		// Blocks made before blocked_by existed were always made by the addressee.
+		`UPDATE friendships SET blocked_by = addressee_id WHERE status = 'blocked' AND blocked_by IS NULL`,
+
+		// Users not tracked yet get their first suggestions from the worker.
+		`INSERT OR IGNORE INTO suggestion_refresh (user_id) SELECT id FROM users`,
+		// Members from before read cursors existed start with everything read.
+		`UPDATE conversation_members SET last_read_message_id = (
+			SELECT COALESCE(MAX(id), 0) FROM messages WHERE conversation_id = conversation_members.conversation_id
+		) WHERE last_read_message_id IS NULL`,
 	}
 
 	for _, query := range queries {
@@ -189,5 +585,126 @@ func runMigrations(db *sql.DB) error {
 		}
 	}
 
+	if err := migrateLikes(db); err != nil {
+		return err
+	}
+	if err := migrateBlockDirections(db); err != nil {
+		return err
+	}
+
+	return runSearchMigrations(db)
+}
+
+// migrateBlockDirections turns blocks made by the addressee around so every
+// block row has the blocker as requester. Older versions let the blocked user
+// send a request back while blocked, so that reverse row is dropped first or
+// the swap would collide with it.
+func migrateBlockDirections(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM friendships WHERE status != 'blocked' AND EXISTS(
+		SELECT 1 FROM friendships b WHERE b.status = 'blocked' AND b.blocked_by = b.addressee_id
+		AND b.requester_id = friendships.addressee_id AND b.addressee_id = friendships.requester_id
+	)`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE friendships SET requester_id = addressee_id, addressee_id = requester_id
+		WHERE status = 'blocked' AND blocked_by = addressee_id`); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// migrateLikes converts the old boolean likes table into "like" reactions and
+// drops it, so the conversion only ever runs once.
+func migrateLikes(db *sql.DB) error {
+	var exists bool
+	if err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'likes')`).
+		Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT OR IGNORE INTO reactions (target_type, target_id, user_id, reaction, created_at)
+		SELECT 'post', post_id, user_id, 'like', created_at FROM likes`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DROP TABLE likes`); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+type searchIndex struct {
+	table   string
+	source  string
+	columns []string
+}
+
+var searchIndexes = []searchIndex{
+	{table: "posts_fts", source: "posts", columns: []string{"content"}},
+	{table: "comments_fts", source: "comments", columns: []string{"content"}},
+	{table: "group_posts_fts", source: "group_posts", columns: []string{"content"}},
+	{table: "groups_fts", source: "groups", columns: []string{"title", "description"}},
+	{table: "users_fts", source: "users", columns: []string{"username", "full_name", "bio"}},
+}
+
+// runSearchMigrations creates the FTS5 indexes as external-content tables over
+// their source tables and keeps them in sync with triggers. A newly created
+// index is rebuilt from the rows that already exist.
+func runSearchMigrations(db *sql.DB) error {
+	for _, idx := range searchIndexes {
+		var exists bool
+		if err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = ?)`,
+			idx.table).Scan(&exists); err != nil {
+			return err
+		}
+
+		cols := strings.Join(idx.columns, ", ")
+		newCols := "new." + strings.Join(idx.columns, ", new.")
+		oldCols := "old." + strings.Join(idx.columns, ", old.")
+
+		queries := []string{
+			fmt.Sprintf(`CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(%s, content='%s', content_rowid='id', prefix='2 3')`,
+				idx.table, cols, idx.source),
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS trg_%s_insert AFTER INSERT ON %s BEGIN
+				INSERT INTO %s(rowid, %s) VALUES (new.id, %s);
+			END`, idx.table, idx.source, idx.table, cols, newCols),
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS trg_%s_delete AFTER DELETE ON %s BEGIN
+				INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.id, %s);
+			END`, idx.table, idx.source, idx.table, idx.table, cols, oldCols),
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS trg_%s_update AFTER UPDATE OF %s ON %s BEGIN
+				INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.id, %s);
+				INSERT INTO %s(rowid, %s) VALUES (new.id, %s);
+			END`, idx.table, cols, idx.source, idx.table, idx.table, cols, oldCols, idx.table, cols, newCols),
+		}
+
+		for _, query := range queries {
+			if _, err := db.Exec(query); err != nil {
+				return fmt.Errorf("search index %s: %w", idx.table, err)
+			}
+		}
+
+		if !exists {
+			if _, err := db.Exec(fmt.Sprintf(`INSERT INTO %s(%s) VALUES ('rebuild')`, idx.table, idx.table)); err != nil {
+				return fmt.Errorf("rebuild search index %s: %w", idx.table, err)
+			}
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// Older versions stored a block on the request row, whichever way it ran, and
+// let the blocked user send a request back.
+func TestMigrateBlockDirectionsDropsReverseRequest(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, query := range []string{
+		`INSERT INTO users (id, email, username, password_hash) VALUES (1, 'a@example.com', 'alice', ''), (2, 'b@example.com', 'bob', '')`,
+		`INSERT INTO friendships (requester_id, addressee_id, status, blocked_by) VALUES (2, 1, 'blocked', 1)`,
+		`INSERT INTO friendships (requester_id, addressee_id, status) VALUES (1, 2, 'pending')`,
+	} {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := db.Init(); err != nil {
+		t.Fatalf("migrating a blocked pair with a reverse request: %v", err)
+	}
+
+	var requesterID, addresseeID, blockedBy int64
+	var status string
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM friendships`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(`SELECT requester_id, addressee_id, status, blocked_by FROM friendships`).
+		Scan(&requesterID, &addresseeID, &status, &blockedBy); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || requesterID != 1 || addresseeID != 2 || status != "blocked" || blockedBy != 1 {
+		t.Fatalf("got %d rows, first %d->%d %s by %d; want one block 1->2 by 1",
+			count, requesterID, addresseeID, status, blockedBy)
+	}
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"socialnet/internal/http/middleware"
 	"socialnet/internal/model"
@@ -56,26 +57,27 @@ func (h *AdminHandler) GetReports(w http.ResponseWriter, r *http.Request) {
 
 func (h *AdminHandler) ReviewReport(w http.ResponseWriter, r *http.Request) {
 	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) < 4 {
+	if len(parts) < 3 {
 		http.Error(w, `{"error":"invalid report ID"}`, http.StatusBadRequest)
 		return
 	}
 
-	reportID, err := strconv.ParseInt(parts[3], 10, 64)
+	reportID, err := strconv.ParseInt(parts[2], 10, 64)
 	if err != nil {
 		http.Error(w, `{"error":"invalid report ID"}`, http.StatusBadRequest)
 		return
 	}
 
 	var req struct {
-		Status model.ReportStatus `json:"status"`
+		Status model.ReportStatus  `json:"status"`
+		Flags  *model.ContentFlags `json:"flags,omitempty"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, `{"error":"invalid request"}`, http.StatusBadRequest)
 		return
 	}
 
-	if err := h.adminService.ReviewReport(reportID, req.Status); err != nil {
+	if err := h.adminService.ReviewReport(reportID, req.Status, req.Flags); err != nil {
 		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
 		return
 	}
@@ -107,6 +109,42 @@ func (h *AdminHandler) DeleteContent(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{"message":"content deleted"}`))
 }
 
+func (h *AdminHandler) SetContentFlags(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 5 {
+		http.Error(w, `{"error":"invalid request"}`, http.StatusBadRequest)
+		return
+	}
+
+	targetID, err := strconv.ParseInt(parts[4], 10, 64)
+	if err != nil {
+		http.Error(w, `{"error":"invalid target ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	var flags model.ContentFlags
+	if err := json.NewDecoder(r.Body).Decode(&flags); err != nil {
+		http.Error(w, `{"error":"invalid request"}`, http.StatusBadRequest)
+		return
+	}
+
+	switch parts[3] {
+	case "post":
+		err = h.adminService.SetPostFlags(targetID, &flags)
+	case "group_post":
+		err = h.adminService.SetGroupPostFlags(targetID, &flags)
+	default:
+		err = errors.New("unsupported target type")
+	}
+	if err != nil {
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"content flags updated"}`))
+}
+
 func (h *AdminHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 	stats, err := h.adminService.GetSiteStats()
 	if err != nil {
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"socialnet/internal/http/middleware"
+	"socialnet/internal/model"
+	"socialnet/internal/service"
+	"strconv"
+	"strings"
+)
+
+type BookmarkHandler struct {
+	bookmarkService *service.BookmarkService
+}
+
+func NewBookmarkHandler(bookmarkService *service.BookmarkService) *BookmarkHandler {
+	return &BookmarkHandler{bookmarkService: bookmarkService}
+}
+
+func (h *BookmarkHandler) Bookmark(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 4 {
+		http.Error(w, "invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	postID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	var create model.BookmarkCreate
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&create); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := h.bookmarkService.Bookmark(userID, postID, &create); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Write([]byte(`{"message":"post bookmarked"}`))
+}
+
+func (h *BookmarkHandler) RemoveBookmark(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 4 {
+		http.Error(w, "invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	postID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.bookmarkService.RemoveBookmark(userID, postID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Write([]byte(`{"message":"bookmark removed"}`))
+}
+
+func (h *BookmarkHandler) GetCollections(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	collections, err := h.bookmarkService.GetCollections(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(collections)
+}
+
+func (h *BookmarkHandler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	var create model.BookmarkCollectionCreate
+	if err := json.NewDecoder(r.Body).Decode(&create); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	collection, err := h.bookmarkService.CreateCollection(userID, &create)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(collection)
+}
+
+func (h *BookmarkHandler) RenameCollection(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	collectionID, ok := parseCollectionID(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid collection ID", http.StatusBadRequest)
+		return
+	}
+
+	var update model.BookmarkCollectionCreate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	collection, err := h.bookmarkService.RenameCollection(collectionID, userID, &update)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(collection)
+}
+
+func (h *BookmarkHandler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	collectionID, ok := parseCollectionID(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid collection ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.bookmarkService.DeleteCollection(collectionID, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Write([]byte(`{"message":"collection deleted"}`))
+}
+
+// parseCollectionID reads the ID from /bookmarks/collections/{id}.
+func parseCollectionID(path string) (int64, bool) {
+	parts := strings.Split(path, "/")
+	if len(parts) < 4 {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(parts[3], 10, 64)
+	return id, err == nil
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"socialnet/internal/http/middleware"
+	"socialnet/internal/model"
+	"socialnet/internal/service"
+	"strconv"
+	"strings"
+)
+
+type FollowHandler struct {
+	followService *service.FollowService
+}
+
+func NewFollowHandler(followService *service.FollowService) *FollowHandler {
+	return &FollowHandler{followService: followService}
+}
+
+func (h *FollowHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	targetID, ok := parseFollowPathID(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	follow, err := h.followService.Follow(userID, targetID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(follow)
+}
+
+func (h *FollowHandler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	targetID, ok := parseFollowPathID(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.followService.Unfollow(userID, targetID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Write([]byte(`{"message":"unfollowed"}`))
+}
+
+func (h *FollowHandler) GetFollowers(w http.ResponseWriter, r *http.Request) {
+	h.listConnections(w, r, h.followService.GetFollowers)
+}
+
+func (h *FollowHandler) GetFollowing(w http.ResponseWriter, r *http.Request) {
+	h.listConnections(w, r, h.followService.GetFollowing)
+}
+
+func (h *FollowHandler) listConnections(w http.ResponseWriter, r *http.Request,
+	list func(targetID, viewerID int64, limit, offset int) ([]*model.UserPublic, error)) {
+	userID := middleware.GetUserID(r)
+
+	targetID, ok := parseFollowPathID(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	offset, _ := strconv.Atoi(q.Get("offset"))
+
+	users, err := list(targetID, userID, limit, offset)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+func (h *FollowHandler) GetPendingRequests(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	requests, err := h.followService.GetPendingRequests(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(requests)
+}
+
+func (h *FollowHandler) AcceptRequest(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	requestID, ok := parseFollowPathID(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid request ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.followService.AcceptRequest(requestID, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Write([]byte(`{"message":"follow request accepted"}`))
+}
+
+func (h *FollowHandler) DeclineRequest(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	requestID, ok := parseFollowPathID(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid request ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.followService.DeclineRequest(requestID, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Write([]byte(`{"message":"follow request declined"}`))
+}
+
+// parseFollowPathID reads the ID from paths such as /users/{id}/follow and
+// /follow-requests/{id}.
+func parseFollowPathID(path string) (int64, bool) {
+	parts := strings.Split(path, "/")
+	if len(parts) < 3 {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	return id, err == nil
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"socialnet/internal/http/middleware"
+	"socialnet/internal/service"
+	"strconv"
+	"strings"
+)
+
+type FriendSuggestionHandler struct {
+	suggestionService *service.FriendSuggestionService
+}
+
+func NewFriendSuggestionHandler(suggestionService *service.FriendSuggestionService) *FriendSuggestionHandler {
+	return &FriendSuggestionHandler{suggestionService: suggestionService}
+}
+
+func (h *FriendSuggestionHandler) GetSuggestions(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	suggestions, err := h.suggestionService.GetSuggestions(userID, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suggestions)
+}
+
+func (h *FriendSuggestionHandler) Dismiss(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 4 {
+		http.Error(w, "invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	dismissedID, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.suggestionService.Dismiss(userID, dismissedID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Write([]byte(`{"message":"suggestion dismissed"}`))
+}
@@ -171,7 +171,9 @@ func (h *GroupHandler) GetGroupPosts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	posts, err := h.groupService.GetGroupPosts(groupID, userID)
+	includeMuted := r.URL.Query().Get("include_muted") == "true"
+
+	posts, err := h.groupService.GetGroupPosts(groupID, userID, includeMuted)
 	if err != nil {
 		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
 		return
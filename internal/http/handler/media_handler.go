@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"socialnet/internal/http/middleware"
+	"socialnet/internal/service"
+	"strings"
+	"time"
+)
+
+type MediaHandler struct {
+	mediaService *service.MediaService
+	uploadDir    string
+}
+
+func NewMediaHandler(mediaService *service.MediaService, uploadDir string) *MediaHandler {
+	return &MediaHandler{mediaService: mediaService, uploadDir: uploadDir}
+}
+
+var allowedUploadExts = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true}
+
+// Upload saves an image and records it as media owned by the uploader. The
+// returned ID is what posts reference as an attachment.
+func (h *MediaHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.uploadDir == "" {
+		http.Error(w, `{"error":"uploads not configured"}`, http.StatusInternalServerError)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		http.Error(w, `{"error":"file too large"}`, http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, `{"error":"no file provided"}`, http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+	if !allowedUploadExts[ext] {
+		http.Error(w, `{"error":"invalid file type"}`, http.StatusBadRequest)
+		return
+	}
+
+	sniff := make([]byte, 512)
+	n, _ := io.ReadFull(file, sniff)
+	contentType := http.DetectContentType(sniff[:n])
+	if !strings.HasPrefix(contentType, "image/") {
+		http.Error(w, `{"error":"invalid file type"}`, http.StatusBadRequest)
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		http.Error(w, `{"error":"failed to save file"}`, http.StatusInternalServerError)
+		return
+	}
+
+	filename := time.Now().Format("20060102150405.000000") + "_" + filepath.Base(header.Filename)
+	savePath := filepath.Join(h.uploadDir, filename)
+
+	dst, err := os.Create(savePath)
+	if err != nil {
+		http.Error(w, `{"error":"failed to save file"}`, http.StatusInternalServerError)
+		return
+	}
+	defer dst.Close()
+
+	size, err := io.Copy(dst, file)
+	if err != nil {
+		http.Error(w, `{"error":"failed to save file"}`, http.StatusInternalServerError)
+		return
+	}
+
+	media, err := h.mediaService.RegisterUpload(userID, "/uploads/"+filename, contentType, size)
+	if err != nil {
+		os.Remove(savePath)
+		http.Error(w, `{"error":"failed to save file"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(media)
+}
@@ -27,7 +27,13 @@ func (h *MessageHandler) StartConversation(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	conversation, err := h.messageService.StartConversation(userID, create.ParticipantID)
+	var conversation *model.Conversation
+	var err error
+	if len(create.ParticipantIDs) > 0 {
+		conversation, err = h.messageService.CreateGroup(userID, &create)
+	} else {
+		conversation, err = h.messageService.StartConversation(userID, create.ParticipantID)
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -95,6 +101,117 @@ func (h *MessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(messages)
 }
 
+func (h *MessageHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	conversationID, _, ok := conversationPath(w, r, false)
+	if !ok {
+		return
+	}
+
+	var read model.MessageRead
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&read); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := h.messageService.MarkRead(conversationID, userID, read.MessageID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Write([]byte(`{"message":"conversation marked as read"}`))
+}
+
+func (h *MessageHandler) MuteConversation(w http.ResponseWriter, r *http.Request) {
+	h.setMuted(w, r, true, "conversation muted")
+}
+
+func (h *MessageHandler) UnmuteConversation(w http.ResponseWriter, r *http.Request) {
+	h.setMuted(w, r, false, "conversation unmuted")
+}
+
+func (h *MessageHandler) setMuted(w http.ResponseWriter, r *http.Request, muted bool, message string) {
+	userID := middleware.GetUserID(r)
+
+	conversationID, _, ok := conversationPath(w, r, false)
+	if !ok {
+		return
+	}
+
+	if err := h.messageService.MuteConversation(conversationID, userID, muted); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Write([]byte(`{"message":"` + message + `"}`))
+}
+
+func (h *MessageHandler) GetUnreadSummary(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	summary, err := h.messageService.GetUnreadSummary(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+func (h *MessageHandler) GetMessageRequests(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	conversations, err := h.messageService.GetMessageRequests(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(conversations)
+}
+
+func (h *MessageHandler) AcceptRequest(w http.ResponseWriter, r *http.Request) {
+	h.handleRequest(w, r, h.messageService.AcceptRequest, "message request accepted")
+}
+
+func (h *MessageHandler) IgnoreRequest(w http.ResponseWriter, r *http.Request) {
+	h.handleRequest(w, r, h.messageService.IgnoreRequest, "message request ignored")
+}
+
+func (h *MessageHandler) BlockRequest(w http.ResponseWriter, r *http.Request) {
+	h.handleRequest(w, r, h.messageService.BlockRequest, "user blocked")
+}
+
+// handleRequest applies action to the conversation in /message-requests/{id}.
+func (h *MessageHandler) handleRequest(w http.ResponseWriter, r *http.Request,
+	action func(conversationID, userID int64) error, message string) {
+	userID := middleware.GetUserID(r)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 3 {
+		http.Error(w, "invalid conversation ID", http.StatusBadRequest)
+		return
+	}
+
+	conversationID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid conversation ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := action(conversationID, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Write([]byte(`{"message":"` + message + `"}`))
+}
+
 func (h *MessageHandler) GetConversations(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r)
 
@@ -107,3 +224,155 @@ func (h *MessageHandler) GetConversations(w http.ResponseWriter, r *http.Request
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(conversations)
 }
+
+func (h *MessageHandler) GetConversation(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	conversationID, _, ok := conversationPath(w, r, false)
+	if !ok {
+		return
+	}
+
+	conversation, err := h.messageService.GetConversation(conversationID, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(conversation)
+}
+
+func (h *MessageHandler) UpdateConversation(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	conversationID, _, ok := conversationPath(w, r, false)
+	if !ok {
+		return
+	}
+
+	var update model.ConversationUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	conversation, err := h.messageService.UpdateGroup(conversationID, userID, &update)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(conversation)
+}
+
+func (h *MessageHandler) AddMembers(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	conversationID, _, ok := conversationPath(w, r, false)
+	if !ok {
+		return
+	}
+
+	var add model.ConversationMembersAdd
+	if err := json.NewDecoder(r.Body).Decode(&add); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	conversation, err := h.messageService.AddMembers(conversationID, userID, &add)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(conversation)
+}
+
+func (h *MessageHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	conversationID, memberID, ok := conversationPath(w, r, true)
+	if !ok {
+		return
+	}
+
+	if err := h.messageService.RemoveMember(conversationID, userID, memberID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Write([]byte(`{"message":"member removed"}`))
+}
+
+func (h *MessageHandler) SetMemberRole(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	conversationID, memberID, ok := conversationPath(w, r, true)
+	if !ok {
+		return
+	}
+
+	var update model.ConversationRoleUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.messageService.SetMemberRole(conversationID, userID, memberID, &update); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Write([]byte(`{"message":"role updated"}`))
+}
+
+func (h *MessageHandler) LeaveConversation(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	conversationID, _, ok := conversationPath(w, r, false)
+	if !ok {
+		return
+	}
+
+	if err := h.messageService.LeaveGroup(conversationID, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Write([]byte(`{"message":"left conversation"}`))
+}
+
+// conversationPath reads the IDs from /conversations/{id}/members/{userID},
+// writing the error response if one is missing.
+func conversationPath(w http.ResponseWriter, r *http.Request, withMember bool) (int64, int64, bool) {
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 3 {
+		http.Error(w, "invalid conversation ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	conversationID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid conversation ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	if !withMember {
+		return conversationID, 0, true
+	}
+
+	if len(parts) < 5 {
+		http.Error(w, "invalid user ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	memberID, err := strconv.ParseInt(parts[4], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid user ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return conversationID, memberID, true
+}
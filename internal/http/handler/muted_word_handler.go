@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"socialnet/internal/http/middleware"
+	"socialnet/internal/model"
+	"socialnet/internal/service"
+	"strconv"
+	"strings"
+)
+
+type MutedWordHandler struct {
+	mutedWordService *service.MutedWordService
+}
+
+func NewMutedWordHandler(mutedWordService *service.MutedWordService) *MutedWordHandler {
+	return &MutedWordHandler{mutedWordService: mutedWordService}
+}
+
+func (h *MutedWordHandler) GetMutedWords(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	words, err := h.mutedWordService.GetMutedWords(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(words)
+}
+
+func (h *MutedWordHandler) Mute(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	var create model.MutedWordCreate
+	if err := json.NewDecoder(r.Body).Decode(&create); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	word, err := h.mutedWordService.Mute(userID, &create)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(word)
+}
+
+func (h *MutedWordHandler) Unmute(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 4 {
+		http.Error(w, "invalid muted word ID", http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid muted word ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.mutedWordService.Unmute(userID, id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Write([]byte(`{"message":"muted word removed"}`))
+}
@@ -20,7 +20,9 @@ func NewNotificationHandler(notificationService *service.NotificationService) *N
 func (h *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r)
 
-	notifications, err := h.notificationService.GetNotifications(userID)
+	includeMuted := r.URL.Query().Get("include_muted") == "true"
+
+	notifications, err := h.notificationService.GetNotifications(userID, includeMuted)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
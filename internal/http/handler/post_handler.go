@@ -118,10 +118,229 @@ func (h *PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{"message":"post deleted"}`))
 }
 
+func (h *PostHandler) VoteInPoll(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 3 {
+		http.Error(w, "invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	postID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	var vote model.PollVote
+	if err := json.NewDecoder(r.Body).Decode(&vote); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	post, err := h.postService.VoteInPoll(postID, userID, &vote)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(post)
+}
+
+func (h *PostHandler) GetScheduledPosts(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	posts, err := h.postService.GetScheduledPosts(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(posts)
+}
+
+// parseScheduledPostID reads the ID from /posts/scheduled/{id}[/publish].
+func parseScheduledPostID(path string) (int64, error) {
+	parts := strings.Split(path, "/")
+	if len(parts) < 4 {
+		return 0, strconv.ErrSyntax
+	}
+	return strconv.ParseInt(parts[3], 10, 64)
+}
+
+func (h *PostHandler) UpdateScheduledPost(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	postID, err := parseScheduledPostID(r.URL.Path)
+	if err != nil {
+		http.Error(w, "invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	var update model.PostSchedule
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	post, err := h.postService.UpdateScheduledPost(postID, userID, &update)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(post)
+}
+
+func (h *PostHandler) CancelScheduledPost(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	postID, err := parseScheduledPostID(r.URL.Path)
+	if err != nil {
+		http.Error(w, "invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.postService.CancelScheduledPost(postID, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Write([]byte(`{"message":"schedule cancelled"}`))
+}
+
+func (h *PostHandler) PublishPost(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	postID, err := parseScheduledPostID(r.URL.Path)
+	if err != nil {
+		http.Error(w, "invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	post, err := h.postService.PublishPost(postID, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(post)
+}
+
+func (h *PostHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	isAdmin := middleware.IsAdmin(r)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 3 {
+		http.Error(w, "invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	postID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	revisions, err := h.postService.GetRevisions(postID, userID, isAdmin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revisions)
+}
+
+func (h *PostHandler) Repost(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 3 {
+		http.Error(w, "invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	postID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	post, err := h.postService.Repost(postID, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(post)
+}
+
+func (h *PostHandler) Unrepost(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 3 {
+		http.Error(w, "invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	postID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.postService.Unrepost(postID, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"repost removed"}`))
+}
+
+func (h *PostHandler) GetBookmarks(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	offset, _ := strconv.Atoi(q.Get("offset"))
+
+	var collectionID *int64
+	if value := q.Get("collection_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid collection ID", http.StatusBadRequest)
+			return
+		}
+		collectionID = &id
+	}
+
+	posts, err := h.postService.GetBookmarks(userID, collectionID, limit, offset)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(posts)
+}
+
 func (h *PostHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r)
 
-	posts, err := h.postService.GetFeed(userID)
+	includeMuted := r.URL.Query().Get("include_muted") == "true"
+
+	posts, err := h.postService.GetFeed(userID, includeMuted)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"socialnet/internal/http/middleware"
+	"socialnet/internal/model"
+	"socialnet/internal/service"
+	"strconv"
+	"strings"
+)
+
+type ReactionHandler struct {
+	reactionService *service.ReactionService
+}
+
+func NewReactionHandler(reactionService *service.ReactionService) *ReactionHandler {
+	return &ReactionHandler{reactionService: reactionService}
+}
+
+var reactionTargets = map[string]model.ReactionTargetType{
+	"posts":    model.ReactionTargetPost,
+	"comments": model.ReactionTargetComment,
+	"messages": model.ReactionTargetMessage,
+}
+
+// parseReactionTarget reads the target from paths such as /posts/{id}/reaction.
+func parseReactionTarget(path string) (model.ReactionTargetType, int64, bool) {
+	parts := strings.Split(path, "/")
+	if len(parts) < 4 {
+		return "", 0, false
+	}
+
+	targetType, ok := reactionTargets[parts[1]]
+	if !ok {
+		return "", 0, false
+	}
+
+	targetID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+
+	return targetType, targetID, true
+}
+
+func (h *ReactionHandler) GetAvailableReactions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.reactionService.GetAvailableReactions())
+}
+
+func (h *ReactionHandler) SetReaction(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	targetType, targetID, ok := parseReactionTarget(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid target ID", http.StatusBadRequest)
+		return
+	}
+
+	var update model.ReactionUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.reactionService.React(targetType, targetID, userID, update.Reaction); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Write([]byte(`{"message":"reaction saved"}`))
+}
+
+func (h *ReactionHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	targetType, targetID, ok := parseReactionTarget(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid target ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.reactionService.RemoveReaction(targetType, targetID, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Write([]byte(`{"message":"reaction removed"}`))
+}
+
+func (h *ReactionHandler) GetReactions(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	targetType, targetID, ok := parseReactionTarget(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid target ID", http.StatusBadRequest)
+		return
+	}
+
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	offset, _ := strconv.Atoi(q.Get("offset"))
+
+	reactions, err := h.reactionService.GetReactions(targetType, targetID, userID, q.Get("reaction"), limit, offset)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reactions)
+}
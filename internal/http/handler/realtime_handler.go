@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"net/http"
+	"socialnet/internal/http/middleware"
+	"socialnet/internal/realtime"
+	"socialnet/internal/service"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+type RealtimeHandler struct {
+	hub            *realtime.Hub
+	messageService *service.MessageService
+	pingInterval   time.Duration
+}
+
+func NewRealtimeHandler(hub *realtime.Hub, messageService *service.MessageService,
+	pingInterval time.Duration) *RealtimeHandler {
+	return &RealtimeHandler{
+		hub:            hub,
+		messageService: messageService,
+		pingInterval:   pingInterval,
+	}
+}
+
+// clientFrame is what clients send over the socket.
+type clientFrame struct {
+	Type           string `json:"type"`
+	ConversationID int64  `json:"conversation_id"`
+	MessageID      int64  `json:"message_id"`
+}
+
+// Connect upgrades the request to a WebSocket. Clients that reconnect pass
+// the last event ID they saw to receive what they missed.
+func (h *RealtimeHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	lastEventID, _ := strconv.ParseInt(r.URL.Query().Get("last_event_id"), 10, 64)
+
+	websocket.Server{
+		// Sockets authenticate with a token rather than cookies, so any
+		// origin may connect.
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			h.serve(conn, userID, lastEventID)
+		},
+	}.ServeHTTP(w, r)
+}
+
+func (h *RealtimeHandler) serve(conn *websocket.Conn, userID, lastEventID int64) {
+	defer conn.Close()
+
+	client, missed := h.hub.Register(userID, lastEventID)
+	defer h.hub.Unregister(client)
+
+	go h.write(conn, client, missed)
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(2 * h.pingInterval))
+
+		var frame clientFrame
+		if err := websocket.JSON.Receive(conn, &frame); err != nil {
+			return
+		}
+
+		var err error
+		switch frame.Type {
+		case "open":
+			// Members viewing a conversation are not notified of its messages.
+			if err = h.messageService.CheckAccess(frame.ConversationID, userID); err == nil {
+				h.hub.SetOpen(client, frame.ConversationID)
+			}
+		case "close":
+			h.hub.SetOpen(client, 0)
+		case "typing":
+			err = h.messageService.Typing(frame.ConversationID, userID)
+		case "read":
+			err = h.messageService.MarkRead(frame.ConversationID, userID, frame.MessageID)
+		}
+		// Anything else, such as "pong", only keeps the connection alive.
+
+		if err != nil {
+			websocket.JSON.Send(conn, &realtime.Event{
+				Type:           realtime.EventError,
+				ConversationID: frame.ConversationID,
+				Data:           err.Error(),
+				CreatedAt:      time.Now(),
+			})
+		}
+	}
+}
+
+// write sends the missed events, then live events and pings, until the hub
+// closes the client.
+func (h *RealtimeHandler) write(conn *websocket.Conn, client *realtime.Client, missed []*realtime.Event) {
+	defer conn.Close()
+
+	ticker := time.NewTicker(h.pingInterval)
+	defer ticker.Stop()
+
+	for _, event := range missed {
+		if h.send(conn, event) != nil {
+			return
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-client.Send:
+			if !ok || h.send(conn, event) != nil {
+				return
+			}
+		case <-ticker.C:
+			if h.send(conn, &realtime.Event{Type: realtime.EventPing, CreatedAt: time.Now()}) != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *RealtimeHandler) send(conn *websocket.Conn, event *realtime.Event) error {
+	conn.SetWriteDeadline(time.Now().Add(h.pingInterval))
+	return websocket.JSON.Send(conn, event)
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"socialnet/internal/http/middleware"
+	"socialnet/internal/model"
+	"socialnet/internal/service"
+	"strconv"
+	"time"
+)
+
+type SearchHandler struct {
+	searchService *service.SearchService
+}
+
+func NewSearchHandler(searchService *service.SearchService) *SearchHandler {
+	return &SearchHandler{searchService: searchService}
+}
+
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	q := r.URL.Query()
+
+	filter := &model.SearchFilter{
+		Query: q.Get("q"),
+		Type:  model.SearchResultType(q.Get("type")),
+	}
+	if filter.Type == "all" {
+		filter.Type = ""
+	}
+
+	if author := q.Get("author_id"); author != "" {
+		authorID, err := strconv.ParseInt(author, 10, 64)
+		if err != nil {
+			http.Error(w, `{"error":"invalid author_id"}`, http.StatusBadRequest)
+			return
+		}
+		filter.AuthorID = authorID
+	}
+
+	if limit := q.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			http.Error(w, `{"error":"invalid limit"}`, http.StatusBadRequest)
+			return
+		}
+		filter.Limit = n
+	}
+
+	var err error
+	if filter.From, err = parseSearchDate(q.Get("from"), false); err != nil {
+		http.Error(w, `{"error":"invalid from date"}`, http.StatusBadRequest)
+		return
+	}
+	if filter.To, err = parseSearchDate(q.Get("to"), true); err != nil {
+		http.Error(w, `{"error":"invalid to date"}`, http.StatusBadRequest)
+		return
+	}
+
+	results, err := h.searchService.Search(userID, filter)
+	if err != nil {
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	if results == nil {
+		results = []*model.SearchResult{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// parseSearchDate accepts RFC 3339 timestamps or plain dates. A plain "to"
+// date includes the whole day.
+func parseSearchDate(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
@@ -63,27 +63,114 @@ func (h *SocialHandler) AcceptFriendRequest(w http.ResponseWriter, r *http.Reque
 func (h *SocialHandler) BlockUser(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r)
 
-	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) < 3 {
+	requestID, ok := parseFriendshipPathID(r.URL.Path)
+	if !ok {
 		http.Error(w, "invalid request ID", http.StatusBadRequest)
 		return
 	}
 
-	requestID, err := strconv.ParseInt(parts[2], 10, 64)
-	if err != nil {
+	if err := h.socialService.BlockUser(requestID, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"user blocked"}`))
+}
+
+func (h *SocialHandler) RemoveFriendRequest(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	requestID, ok := parseFriendshipPathID(r.URL.Path)
+	if !ok {
 		http.Error(w, "invalid request ID", http.StatusBadRequest)
 		return
 	}
 
-	if err := h.socialService.BlockUser(requestID, userID); err != nil {
+	if err := h.socialService.RemoveFriendRequest(requestID, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Write([]byte(`{"message":"friend request removed"}`))
+}
+
+func (h *SocialHandler) Unfriend(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	friendID, ok := parseFriendshipPathID(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.socialService.Unfriend(userID, friendID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Write([]byte(`{"message":"unfriended"}`))
+}
+
+func (h *SocialHandler) BlockUserByID(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	targetID, ok := parseFriendshipPathID(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.socialService.BlockUserByID(userID, targetID); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(`{"message":"user blocked"}`))
 }
 
+func (h *SocialHandler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	targetID, ok := parseFriendshipPathID(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.socialService.UnblockUser(userID, targetID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Write([]byte(`{"message":"user unblocked"}`))
+}
+
+func (h *SocialHandler) GetBlockedUsers(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	users, err := h.socialService.GetBlockedUsers(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+// parseFriendshipPathID reads the ID from paths such as /friends/{id}/block
+// and /users/{id}/block.
+func parseFriendshipPathID(path string) (int64, bool) {
+	parts := strings.Split(path, "/")
+	if len(parts) < 3 {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	return id, err == nil
+}
+
 func (h *SocialHandler) GetFriends(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r)
 
@@ -97,6 +184,25 @@ func (h *SocialHandler) GetFriends(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(friends)
 }
 
+func (h *SocialHandler) GetMutualFriends(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	otherID, ok := parseFriendshipPathID(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	friends, err := h.socialService.GetMutualFriends(userID, otherID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(friends)
+}
+
 func (h *SocialHandler) GetPendingRequests(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r)
 
@@ -110,6 +216,19 @@ func (h *SocialHandler) GetPendingRequests(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(requests)
 }
 
+func (h *SocialHandler) GetOutgoingRequests(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	requests, err := h.socialService.GetOutgoingRequests(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(requests)
+}
+
 func (h *SocialHandler) LikePost(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r)
 
@@ -190,7 +309,65 @@ func (h *SocialHandler) CommentOnPost(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(comment)
 }
 
+func (h *SocialHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 3 {
+		http.Error(w, "invalid comment ID", http.StatusBadRequest)
+		return
+	}
+
+	commentID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid comment ID", http.StatusBadRequest)
+		return
+	}
+
+	var update model.CommentUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.socialService.UpdateComment(commentID, userID, &update)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comment)
+}
+
+func (h *SocialHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	isAdmin := middleware.IsAdmin(r)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 3 {
+		http.Error(w, "invalid comment ID", http.StatusBadRequest)
+		return
+	}
+
+	commentID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid comment ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.socialService.DeleteComment(commentID, userID, isAdmin); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"comment deleted"}`))
+}
+
 func (h *SocialHandler) GetComments(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
 	parts := strings.Split(r.URL.Path, "/")
 	if len(parts) < 3 {
 		http.Error(w, "invalid post ID", http.StatusBadRequest)
@@ -203,9 +380,9 @@ func (h *SocialHandler) GetComments(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	comments, err := h.socialService.GetComments(postID)
+	comments, err := h.socialService.GetComments(postID, userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
 
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"socialnet/internal/http/middleware"
+	"socialnet/internal/model"
+	"socialnet/internal/service"
+	"strconv"
+	"strings"
+)
+
+type StoryHandler struct {
+	storyService *service.StoryService
+}
+
+func NewStoryHandler(storyService *service.StoryService) *StoryHandler {
+	return &StoryHandler{storyService: storyService}
+}
+
+// parseStoryID reads the ID from paths such as /stories/{id}/views.
+func parseStoryID(path string) (int64, bool) {
+	parts := strings.Split(path, "/")
+	if len(parts) < 3 {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	return id, err == nil
+}
+
+func (h *StoryHandler) CreateStory(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	var create model.StoryCreate
+	if err := json.NewDecoder(r.Body).Decode(&create); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	story, err := h.storyService.CreateStory(userID, &create)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(story)
+}
+
+func (h *StoryHandler) GetTray(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	tray, err := h.storyService.GetTray(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tray)
+}
+
+func (h *StoryHandler) GetStory(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	storyID, ok := parseStoryID(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	story, err := h.storyService.GetStory(storyID, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(story)
+}
+
+func (h *StoryHandler) GetViews(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	storyID, ok := parseStoryID(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	views, err := h.storyService.GetViews(storyID, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(views)
+}
+
+func (h *StoryHandler) Reply(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	storyID, ok := parseStoryID(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	var reply model.StoryReply
+	if err := json.NewDecoder(r.Body).Decode(&reply); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	message, err := h.storyService.Reply(storyID, userID, &reply)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(message)
+}
+
+func (h *StoryHandler) DeleteStory(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	storyID, ok := parseStoryID(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.storyService.DeleteStory(storyID, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Write([]byte(`{"message":"story deleted"}`))
+}
@@ -90,6 +90,37 @@ func (h *UserHandler) UpdatePrivacySettings(w http.ResponseWriter, r *http.Reque
 	w.Write([]byte(`{"message":"privacy settings updated"}`))
 }
 
+func (h *UserHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	prefs, err := h.userService.GetPreferences(userID)
+	if err != nil {
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
+
+func (h *UserHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	var prefs model.UserPreferences
+	if err := json.NewDecoder(r.Body).Decode(&prefs); err != nil {
+		http.Error(w, `{"error":"invalid request"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.userService.UpdatePreferences(userID, &prefs); err != nil {
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"preferences updated"}`))
+}
+
 func (h *UserHandler) SetEmojiAvatar(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r)
 
@@ -128,7 +159,7 @@ func (h *UserHandler) GetUsersWithEmoji(w http.ResponseWriter, r *http.Request)
 	}
 
 	emojiID := parts[2]
-	users, err := h.userService.GetUsersWithEmoji(emojiID)
+	users, err := h.userService.GetUsersWithEmoji(emojiID, middleware.GetUserID(r))
 	if err != nil {
 		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
 		return
@@ -158,6 +189,19 @@ func (h *UserHandler) UpdateOnlineStatus(w http.ResponseWriter, r *http.Request)
 	w.Write([]byte(`{"message":"status updated"}`))
 }
 
+func (h *UserHandler) AutocompleteUsers(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	users, err := h.userService.AutocompleteUsers(r.URL.Query().Get("q"), userID)
+	if err != nil {
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
 func (h *UserHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	if query == "" {
@@ -165,7 +209,7 @@ func (h *UserHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	users, err := h.userService.SearchUsers(query)
+	users, err := h.userService.SearchUsers(query, middleware.GetUserID(r))
 	if err != nil {
 		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
 		return
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"socialnet/internal/http/middleware"
+	"socialnet/internal/model"
+	"socialnet/internal/service"
+	"strconv"
+	"strings"
+)
+
+type UserMuteHandler struct {
+	userMuteService *service.UserMuteService
+}
+
+func NewUserMuteHandler(userMuteService *service.UserMuteService) *UserMuteHandler {
+	return &UserMuteHandler{userMuteService: userMuteService}
+}
+
+func (h *UserMuteHandler) GetMutes(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	mutes, err := h.userMuteService.GetMutes(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mutes)
+}
+
+func (h *UserMuteHandler) Mute(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	var create model.UserMuteCreate
+	if err := json.NewDecoder(r.Body).Decode(&create); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	mute, err := h.userMuteService.Mute(userID, &create)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(mute)
+}
+
+func (h *UserMuteHandler) Unmute(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 4 {
+		http.Error(w, "invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	mutedUserID, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.userMuteService.Unmute(userID, mutedUserID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Write([]byte(`{"message":"user unmuted"}`))
+}
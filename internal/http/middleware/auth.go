@@ -47,6 +47,18 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	})
 }
 
+// AuthenticateSocket is Authenticate for WebSocket upgrades. Browsers cannot
+// set headers on a WebSocket, so the token may also be passed as the
+// access_token query parameter.
+func (m *AuthMiddleware) AuthenticateSocket(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("access_token"); token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		m.Authenticate(next).ServeHTTP(w, r)
+	})
+}
+
 func GetUserID(r *http.Request) int64 {
 	userID, ok := r.Context().Value(UserIDKey).(int64)
 	if !ok {
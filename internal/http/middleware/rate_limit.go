@@ -26,7 +26,6 @@ func (rl *RateLimiter) Limit(next http.Handler) http.Handler {
 		ip := r.RemoteAddr
 
 		rl.mu.Lock()
-		defer rl.mu.Unlock()
 
 		now := time.Now()
 		windowStart := now.Add(-rl.window)
@@ -40,12 +39,16 @@ func (rl *RateLimiter) Limit(next http.Handler) http.Handler {
 		}
 
 		if len(validRequests) >= rl.limit {
+			rl.mu.Unlock()
 			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
 			return
 		}
 
 		validRequests = append(validRequests, now)
 		rl.requests[ip] = validRequests
+		// Unlock before serving, or one slow request (or an open WebSocket)
+		// would hold up every other client.
+		rl.mu.Unlock()
 
 		next.ServeHTTP(w, r)
 	})
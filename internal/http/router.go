@@ -1,29 +1,37 @@
 package http
 
 import (
-	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"socialnet/internal/http/handler"
 	"socialnet/internal/http/middleware"
 	"strings"
-	"time"
 )
 
 type Router struct {
-	authHandler    *handler.AuthHandler
-	userHandler    *handler.UserHandler
-	postHandler    *handler.PostHandler
-	socialHandler  *handler.SocialHandler
-	messageHandler *handler.MessageHandler
-	groupHandler   *handler.GroupHandler
-	notifHandler   *handler.NotificationHandler
-	adminHandler   *handler.AdminHandler
-	authMiddleware *middleware.AuthMiddleware
-	rateLimiter    *middleware.RateLimiter
-	uploadDir      string
-	frontendDir    string
+	authHandler       *handler.AuthHandler
+	userHandler       *handler.UserHandler
+	postHandler       *handler.PostHandler
+	socialHandler     *handler.SocialHandler
+	messageHandler    *handler.MessageHandler
+	groupHandler      *handler.GroupHandler
+	notifHandler      *handler.NotificationHandler
+	adminHandler      *handler.AdminHandler
+	searchHandler     *handler.SearchHandler
+	reactionHandler   *handler.ReactionHandler
+	mediaHandler      *handler.MediaHandler
+	bookmarkHandler   *handler.BookmarkHandler
+	storyHandler      *handler.StoryHandler
+	mutedWordHandler  *handler.MutedWordHandler
+	followHandler     *handler.FollowHandler
+	userMuteHandler   *handler.UserMuteHandler
+	suggestionHandler *handler.FriendSuggestionHandler
+	realtimeHandler   *handler.RealtimeHandler
+	authMiddleware    *middleware.AuthMiddleware
+	rateLimiter       *middleware.RateLimiter
+	uploadDir         string
+	frontendDir       string
 }
 
 func NewRouter(
@@ -35,24 +43,44 @@ func NewRouter(
 	groupHandler *handler.GroupHandler,
 	notifHandler *handler.NotificationHandler,
 	adminHandler *handler.AdminHandler,
+	searchHandler *handler.SearchHandler,
+	reactionHandler *handler.ReactionHandler,
+	mediaHandler *handler.MediaHandler,
+	bookmarkHandler *handler.BookmarkHandler,
+	storyHandler *handler.StoryHandler,
+	mutedWordHandler *handler.MutedWordHandler,
+	followHandler *handler.FollowHandler,
+	userMuteHandler *handler.UserMuteHandler,
+	suggestionHandler *handler.FriendSuggestionHandler,
+	realtimeHandler *handler.RealtimeHandler,
 	authMiddleware *middleware.AuthMiddleware,
 	rateLimiter *middleware.RateLimiter,
 	uploadDir string,
 	frontendDir string,
 ) *Router {
 	return &Router{
-		authHandler:    authHandler,
-		userHandler:    userHandler,
-		postHandler:    postHandler,
-		socialHandler:  socialHandler,
-		messageHandler: messageHandler,
-		groupHandler:   groupHandler,
-		notifHandler:   notifHandler,
-		adminHandler:   adminHandler,
-		authMiddleware: authMiddleware,
-		rateLimiter:    rateLimiter,
-		uploadDir:      uploadDir,
-		frontendDir:    frontendDir,
+		authHandler:       authHandler,
+		userHandler:       userHandler,
+		postHandler:       postHandler,
+		socialHandler:     socialHandler,
+		messageHandler:    messageHandler,
+		groupHandler:      groupHandler,
+		notifHandler:      notifHandler,
+		adminHandler:      adminHandler,
+		searchHandler:     searchHandler,
+		reactionHandler:   reactionHandler,
+		mediaHandler:      mediaHandler,
+		bookmarkHandler:   bookmarkHandler,
+		storyHandler:      storyHandler,
+		mutedWordHandler:  mutedWordHandler,
+		followHandler:     followHandler,
+		userMuteHandler:   userMuteHandler,
+		suggestionHandler: suggestionHandler,
+		realtimeHandler:   realtimeHandler,
+		authMiddleware:    authMiddleware,
+		rateLimiter:       rateLimiter,
+		uploadDir:         uploadDir,
+		frontendDir:       frontendDir,
 	}
 }
 
@@ -65,7 +93,54 @@ func (rt *Router) Setup() http.Handler {
 	apiMux.HandleFunc("/auth/password-requirements", rt.authHandler.GetPasswordRequirements)
 
 	apiMux.Handle("/users/search", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.userHandler.SearchUsers)))
-	apiMux.Handle("/users/", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.userHandler.GetProfile)))
+	apiMux.Handle("/users/autocomplete", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.userHandler.AutocompleteUsers)))
+	apiMux.Handle("/users/", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/follow"):
+			switch r.Method {
+			case http.MethodPost:
+				rt.followHandler.Follow(w, r)
+			case http.MethodDelete:
+				rt.followHandler.Unfollow(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		case strings.HasSuffix(r.URL.Path, "/block"):
+			switch r.Method {
+			case http.MethodPost:
+				rt.socialHandler.BlockUserByID(w, r)
+			case http.MethodDelete:
+				rt.socialHandler.UnblockUser(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		case strings.HasSuffix(r.URL.Path, "/friend"):
+			if r.Method != http.MethodDelete {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			rt.socialHandler.Unfriend(w, r)
+		case strings.HasSuffix(r.URL.Path, "/mutual-friends"):
+			rt.socialHandler.GetMutualFriends(w, r)
+		case strings.HasSuffix(r.URL.Path, "/followers"):
+			rt.followHandler.GetFollowers(w, r)
+		case strings.HasSuffix(r.URL.Path, "/following"):
+			rt.followHandler.GetFollowing(w, r)
+		default:
+			rt.userHandler.GetProfile(w, r)
+		}
+	})))
+	apiMux.Handle("/follow-requests", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.followHandler.GetPendingRequests)))
+	apiMux.Handle("/follow-requests/", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			rt.followHandler.AcceptRequest(w, r)
+		case http.MethodDelete:
+			rt.followHandler.DeclineRequest(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
 
 	apiMux.Handle("/profile", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -80,10 +155,21 @@ func (rt *Router) Setup() http.Handler {
 
 	apiMux.Handle("/profile/privacy", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.userHandler.UpdatePrivacySettings)))
 	apiMux.Handle("/profile/emoji", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.userHandler.SetEmojiAvatar)))
+	apiMux.Handle("/profile/preferences", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			rt.userHandler.GetPreferences(w, r)
+		case http.MethodPut:
+			rt.userHandler.UpdatePreferences(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
 	apiMux.Handle("/profile/status", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.userHandler.UpdateOnlineStatus)))
 
 	apiMux.Handle("/emojis", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.userHandler.GetEmojis)))
 	apiMux.Handle("/emojis/", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.userHandler.GetUsersWithEmoji)))
+	apiMux.Handle("/reactions", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.reactionHandler.GetAvailableReactions)))
 
 	apiMux.Handle("/posts", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -96,7 +182,74 @@ func (rt *Router) Setup() http.Handler {
 		}
 	})))
 
+	apiMux.Handle("/posts/scheduled", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.postHandler.GetScheduledPosts)))
+	apiMux.Handle("/posts/scheduled/", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/publish") {
+			if r.Method != http.MethodPost {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			rt.postHandler.PublishPost(w, r)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPut:
+			rt.postHandler.UpdateScheduledPost(w, r)
+		case http.MethodDelete:
+			rt.postHandler.CancelScheduledPost(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
 	apiMux.Handle("/posts/", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rt.routeReaction(w, r) {
+			return
+		}
+
+		if strings.HasSuffix(r.URL.Path, "/poll/vote") {
+			if r.Method != http.MethodPost {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			rt.postHandler.VoteInPoll(w, r)
+			return
+		}
+
+		if strings.HasSuffix(r.URL.Path, "/bookmark") {
+			switch r.Method {
+			case http.MethodPost:
+				rt.bookmarkHandler.Bookmark(w, r)
+			case http.MethodDelete:
+				rt.bookmarkHandler.RemoveBookmark(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+			return
+		}
+
+		if strings.HasSuffix(r.URL.Path, "/revisions") {
+			if r.Method != http.MethodGet {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			rt.postHandler.GetRevisions(w, r)
+			return
+		}
+
+		if strings.HasSuffix(r.URL.Path, "/repost") {
+			switch r.Method {
+			case http.MethodPost:
+				rt.postHandler.Repost(w, r)
+			case http.MethodDelete:
+				rt.postHandler.Unrepost(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+			return
+		}
+
 		switch r.Method {
 		case http.MethodGet:
 			rt.postHandler.GetPost(w, r)
@@ -109,6 +262,102 @@ func (rt *Router) Setup() http.Handler {
 		}
 	})))
 
+	apiMux.Handle("/bookmarks", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.postHandler.GetBookmarks)))
+	apiMux.Handle("/bookmarks/collections", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			rt.bookmarkHandler.GetCollections(w, r)
+		case http.MethodPost:
+			rt.bookmarkHandler.CreateCollection(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+	apiMux.Handle("/bookmarks/collections/", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			rt.bookmarkHandler.RenameCollection(w, r)
+		case http.MethodDelete:
+			rt.bookmarkHandler.DeleteCollection(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	apiMux.Handle("/mutes/words", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			rt.mutedWordHandler.GetMutedWords(w, r)
+		case http.MethodPost:
+			rt.mutedWordHandler.Mute(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+	apiMux.Handle("/mutes/words/", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		rt.mutedWordHandler.Unmute(w, r)
+	})))
+	apiMux.Handle("/mutes/users", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			rt.userMuteHandler.GetMutes(w, r)
+		case http.MethodPost:
+			rt.userMuteHandler.Mute(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+	apiMux.Handle("/mutes/users/", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		rt.userMuteHandler.Unmute(w, r)
+	})))
+
+	apiMux.Handle("/stories", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			rt.storyHandler.GetTray(w, r)
+		case http.MethodPost:
+			rt.storyHandler.CreateStory(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+	apiMux.Handle("/stories/", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/views") {
+			if r.Method != http.MethodGet {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			rt.storyHandler.GetViews(w, r)
+			return
+		}
+
+		if strings.HasSuffix(r.URL.Path, "/reply") {
+			if r.Method != http.MethodPost {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			rt.storyHandler.Reply(w, r)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			rt.storyHandler.GetStory(w, r)
+		case http.MethodDelete:
+			rt.storyHandler.DeleteStory(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
 	apiMux.Handle("/likes/", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
@@ -121,11 +370,19 @@ func (rt *Router) Setup() http.Handler {
 	})))
 
 	apiMux.Handle("/comments/", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rt.routeReaction(w, r) {
+			return
+		}
+
 		switch r.Method {
 		case http.MethodGet:
 			rt.socialHandler.GetComments(w, r)
 		case http.MethodPost:
 			rt.socialHandler.CommentOnPost(w, r)
+		case http.MethodPut:
+			rt.socialHandler.UpdateComment(w, r)
+		case http.MethodDelete:
+			rt.socialHandler.DeleteComment(w, r)
 		default:
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		}
@@ -133,14 +390,33 @@ func (rt *Router) Setup() http.Handler {
 
 	apiMux.Handle("/friends", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.socialHandler.GetFriends)))
 	apiMux.Handle("/friends/requests", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.socialHandler.GetPendingRequests)))
+	apiMux.Handle("/friends/requests/outgoing", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.socialHandler.GetOutgoingRequests)))
+	apiMux.Handle("/friends/blocked", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.socialHandler.GetBlockedUsers)))
+	apiMux.Handle("/friends/suggestions", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.suggestionHandler.GetSuggestions)))
+	apiMux.Handle("/friends/suggestions/", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		rt.suggestionHandler.Dismiss(w, r)
+	})))
 	apiMux.Handle("/friends/", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/block") {
+			if r.Method != http.MethodPut {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			rt.socialHandler.BlockUser(w, r)
+			return
+		}
+
 		switch r.Method {
 		case http.MethodPost:
 			rt.socialHandler.SendFriendRequest(w, r)
 		case http.MethodPut:
 			rt.socialHandler.AcceptFriendRequest(w, r)
 		case http.MethodDelete:
-			rt.socialHandler.BlockUser(w, r)
+			rt.socialHandler.RemoveFriendRequest(w, r)
 		default:
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		}
@@ -157,9 +433,32 @@ func (rt *Router) Setup() http.Handler {
 		}
 	})))
 
+	apiMux.Handle("/ws", rt.authMiddleware.AuthenticateSocket(http.HandlerFunc(rt.realtimeHandler.Connect)))
+	apiMux.Handle("/message-requests", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.messageHandler.GetMessageRequests)))
+	apiMux.Handle("/message-requests/", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/block") {
+			if r.Method != http.MethodPut {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			rt.messageHandler.BlockRequest(w, r)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPut:
+			rt.messageHandler.AcceptRequest(w, r)
+		case http.MethodDelete:
+			rt.messageHandler.IgnoreRequest(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
 	apiMux.Handle("/conversations/", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
-		if strings.HasSuffix(path, "/messages") {
+		switch {
+		case strings.HasSuffix(path, "/messages"):
 			switch r.Method {
 			case http.MethodGet:
 				rt.messageHandler.GetMessages(w, r)
@@ -168,11 +467,66 @@ func (rt *Router) Setup() http.Handler {
 			default:
 				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 			}
-		} else {
+		case strings.HasSuffix(path, "/read"):
+			if r.Method != http.MethodPost {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			rt.messageHandler.MarkRead(w, r)
+		case strings.HasSuffix(path, "/mute"):
+			switch r.Method {
+			case http.MethodPut:
+				rt.messageHandler.MuteConversation(w, r)
+			case http.MethodDelete:
+				rt.messageHandler.UnmuteConversation(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		case strings.HasSuffix(path, "/leave"):
+			if r.Method != http.MethodPost {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			rt.messageHandler.LeaveConversation(w, r)
+		case strings.HasSuffix(path, "/members"):
+			if r.Method != http.MethodPost {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			rt.messageHandler.AddMembers(w, r)
+		case strings.Contains(path, "/members/"):
+			switch r.Method {
+			case http.MethodPut:
+				rt.messageHandler.SetMemberRole(w, r)
+			case http.MethodDelete:
+				rt.messageHandler.RemoveMember(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		case path == "/conversations/":
 			rt.messageHandler.GetConversations(w, r)
+		case path == "/conversations/unread":
+			rt.messageHandler.GetUnreadSummary(w, r)
+		default:
+			switch r.Method {
+			case http.MethodGet:
+				rt.messageHandler.GetConversation(w, r)
+			case http.MethodPut:
+				rt.messageHandler.UpdateConversation(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		}
+	})))
+
+	apiMux.Handle("/messages/", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !rt.routeReaction(w, r) {
+			http.NotFound(w, r)
 		}
 	})))
 
+	apiMux.Handle("/search", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.searchHandler.Search)))
+
 	apiMux.Handle("/groups", rt.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
@@ -252,6 +606,7 @@ func (rt *Router) Setup() http.Handler {
 	apiMux.Handle("/reports/", rt.authMiddleware.Authenticate(rt.authMiddleware.RequireAdmin(http.HandlerFunc(rt.adminHandler.ReviewReport))))
 
 	apiMux.Handle("/admin/delete/", rt.authMiddleware.Authenticate(rt.authMiddleware.RequireAdmin(http.HandlerFunc(rt.adminHandler.DeleteContent))))
+	apiMux.Handle("/admin/flags/", rt.authMiddleware.Authenticate(rt.authMiddleware.RequireAdmin(http.HandlerFunc(rt.adminHandler.SetContentFlags))))
 	apiMux.Handle("/admin/stats", rt.authMiddleware.Authenticate(rt.authMiddleware.RequireAdmin(http.HandlerFunc(rt.adminHandler.GetStats))))
 	apiMux.Handle("/admin/grant", rt.authMiddleware.Authenticate(rt.authMiddleware.RequireAdmin(http.HandlerFunc(rt.adminHandler.GrantAdmin))))
 	apiMux.Handle("/admin/broadcast", rt.authMiddleware.Authenticate(rt.authMiddleware.RequireAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -267,7 +622,7 @@ func (rt *Router) Setup() http.Handler {
 
 	apiMux.Handle("/admin/broadcast/emoji/", rt.authMiddleware.Authenticate(rt.authMiddleware.RequireAdmin(http.HandlerFunc(rt.adminHandler.BroadcastToEmoji))))
 
-	apiMux.Handle("/upload", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.handleUpload)))
+	apiMux.Handle("/upload", rt.authMiddleware.Authenticate(http.HandlerFunc(rt.mediaHandler.Upload)))
 
 	// Top-level mux: /api → API, /uploads → static, everything else → SPA
 	topMux := http.NewServeMux()
@@ -297,51 +652,27 @@ func (rt *Router) Setup() http.Handler {
 	return rt.rateLimiter.Limit(topMux)
 }
 
-func (rt *Router) handleUpload(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	if rt.uploadDir == "" {
-		http.Error(w, `{"error":"uploads not configured"}`, http.StatusInternalServerError)
-		return
-	}
-
-	if err := r.ParseMultipartForm(10 << 20); err != nil {
-		http.Error(w, `{"error":"file too large"}`, http.StatusBadRequest)
-		return
-	}
-
-	file, header, err := r.FormFile("file")
-	if err != nil {
-		http.Error(w, `{"error":"no file provided"}`, http.StatusBadRequest)
-		return
-	}
-	defer file.Close()
-
-	ext := filepath.Ext(header.Filename)
-	filename := time.Now().Format("20060102150405") + "_" + header.Filename
-	savePath := filepath.Join(rt.uploadDir, filename)
-
-	allowedExts := map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true}
-	if !allowedExts[strings.ToLower(ext)] {
-		http.Error(w, `{"error":"invalid file type"}`, http.StatusBadRequest)
-		return
-	}
-
-	dst, err := os.Create(savePath)
-	if err != nil {
-		http.Error(w, `{"error":"failed to save file"}`, http.StatusInternalServerError)
-		return
-	}
-	defer dst.Close()
-
-	if _, err := io.Copy(dst, file); err != nil {
-		http.Error(w, `{"error":"failed to save file"}`, http.StatusInternalServerError)
-		return
+// routeReaction serves the /reaction and /reactions subpaths shared by posts,
+// comments and messages. It reports whether the request was handled.
+func (rt *Router) routeReaction(w http.ResponseWriter, r *http.Request) bool {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/reaction"):
+		switch r.Method {
+		case http.MethodPut:
+			rt.reactionHandler.SetReaction(w, r)
+		case http.MethodDelete:
+			rt.reactionHandler.RemoveReaction(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	case strings.HasSuffix(r.URL.Path, "/reactions"):
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return true
+		}
+		rt.reactionHandler.GetReactions(w, r)
+	default:
+		return false
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"url":"/uploads/` + filename + `"}`))
+	return true
 }
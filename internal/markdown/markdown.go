@@ -0,0 +1,362 @@
+// Package markdown renders the Markdown subset allowed in user content:
+// bold, italic, inline code, fenced code blocks, links, lists and
+// blockquotes. Raw HTML in the source is always escaped, so the output is
+// safe to insert into a page as is.
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxDepth bounds how deeply blockquotes and inline spans may nest.
+const maxDepth = 8
+
+// Render converts src to HTML. Links only keep http, https and mailto
+// targets and are marked rel="nofollow"; anything else is shown as text.
+func Render(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(src, "\n"), 0)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func renderBlocks(b *strings.Builder, lines []string, depth int) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+
+		case isFence(line):
+			end := i + 1
+			for end < len(lines) && !isFence(lines[end]) {
+				end++
+			}
+			b.WriteString("<pre><code>")
+			b.WriteString(html.EscapeString(strings.Join(lines[i+1:min(end, len(lines))], "\n")))
+			b.WriteString("</code></pre>\n")
+			i = end + 1
+
+		case isQuote(line) && depth < maxDepth:
+			var inner []string
+			for ; i < len(lines) && isQuote(lines[i]); i++ {
+				inner = append(inner, stripQuote(lines[i]))
+			}
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, inner, depth+1)
+			b.WriteString("</blockquote>\n")
+
+		case isListItem(line):
+			_, ordered, start := listItem(line)
+			tag := "ul"
+			if ordered {
+				tag = "ol"
+			}
+
+			b.WriteString("<" + tag)
+			if ordered && start != 1 {
+				b.WriteString(` start="` + strconv.Itoa(start) + `"`)
+			}
+			b.WriteString(">\n")
+			for ; i < len(lines); i++ {
+				item, itemOrdered, _ := listItem(lines[i])
+				if !isListItem(lines[i]) || itemOrdered != ordered {
+					break
+				}
+				b.WriteString("<li>")
+				renderInline(b, item, 0, false)
+				b.WriteString("</li>\n")
+			}
+			b.WriteString("</" + tag + ">\n")
+
+		default:
+			b.WriteString("<p>")
+			for first := true; i < len(lines) && !startsBlock(lines[i], depth); i++ {
+				if !first {
+					b.WriteString("<br>\n")
+				}
+				renderInline(b, strings.TrimSpace(lines[i]), 0, false)
+				first = false
+			}
+			b.WriteString("</p>\n")
+		}
+	}
+}
+
+// startsBlock reports whether line ends a paragraph.
+func startsBlock(line string, depth int) bool {
+	return strings.TrimSpace(line) == "" || isFence(line) || isListItem(line) ||
+		(isQuote(line) && depth < maxDepth)
+}
+
+func isFence(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "```")
+}
+
+func isQuote(line string) bool {
+	return strings.HasPrefix(strings.TrimLeft(line, " "), ">")
+}
+
+func stripQuote(line string) string {
+	line = strings.TrimPrefix(strings.TrimLeft(line, " "), ">")
+	return strings.TrimPrefix(line, " ")
+}
+
+func isListItem(line string) bool {
+	_, _, start := listItem(line)
+	return start >= 0
+}
+
+// listItem splits a list line into its text, whether the list is ordered and
+// the item number. start is -1 when the line is not a list item.
+func listItem(line string) (text string, ordered bool, start int) {
+	line = strings.TrimLeft(line, " ")
+
+	if len(line) >= 2 && strings.ContainsRune("-*+", rune(line[0])) && line[1] == ' ' {
+		return strings.TrimSpace(line[2:]), false, 0
+	}
+
+	digits := 0
+	for digits < len(line) && digits < 9 && line[digits] >= '0' && line[digits] <= '9' {
+		digits++
+	}
+	if digits > 0 && len(line) > digits+1 && (line[digits] == '.' || line[digits] == ')') && line[digits+1] == ' ' {
+		n, _ := strconv.Atoi(line[:digits])
+		return strings.TrimSpace(line[digits+2:]), true, n
+	}
+
+	return "", false, -1
+}
+
+// renderInline writes one line of text, turning emphasis, code spans and
+// links into HTML and escaping everything else.
+func renderInline(b *strings.Builder, s string, depth int, inLink bool) {
+	plain := 0
+	flush := func(end int) {
+		b.WriteString(html.EscapeString(s[plain:end]))
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		next := i + 1
+		written := false
+
+		switch {
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			flush(i)
+			b.WriteString(html.EscapeString(s[i+1 : i+2]))
+			next, written = i+2, true
+
+		case c == '`':
+			run := countRun(s, i, '`')
+			if end := strings.Index(s[i+run:], s[i:i+run]); end >= 0 {
+				flush(i)
+				b.WriteString("<code>")
+				b.WriteString(html.EscapeString(s[i+run : i+run+end]))
+				b.WriteString("</code>")
+				next, written = i+run+end+run, true
+			} else {
+				next = i + run
+			}
+
+		case (c == '*' || c == '_') && depth < maxDepth:
+			if end, inner, tag, ok := emphasis(s, i); ok {
+				flush(i)
+				b.WriteString("<" + tag + ">")
+				renderInline(b, inner, depth+1, inLink)
+				b.WriteString("</" + tag + ">")
+				next, written = end, true
+			} else {
+				next = i + countRun(s, i, c)
+			}
+
+		case c == '[' && !inLink && depth < maxDepth:
+			if end, text, href, ok := link(s, i); ok {
+				flush(i)
+				b.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow">`)
+				renderInline(b, text, depth+1, true)
+				b.WriteString("</a>")
+				next, written = end, true
+			}
+
+		case (c == 'h' || c == 'H') && !inLink:
+			if end, href, ok := autolink(s, i); ok {
+				flush(i)
+				b.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow">`)
+				b.WriteString(html.EscapeString(s[i:end]))
+				b.WriteString("</a>")
+				next, written = end, true
+			}
+		}
+
+		if written {
+			plain = next
+		}
+		i = next
+	}
+	flush(len(s))
+}
+
+// emphasis matches a *italic*, **bold** or underscore span opening at i. The
+// span may not start or end with a space, and underscores only count at word
+// boundaries so snake_case is left alone.
+func emphasis(s string, i int) (end int, inner, tag string, ok bool) {
+	c := s[i]
+	size := min(countRun(s, i, c), 2)
+	delim := s[i : i+size]
+	open := i + size
+
+	if open >= len(s) || isSpace(s[open:]) {
+		return 0, "", "", false
+	}
+	if c == '_' && i > 0 && isWordRune(lastRune(s[:i])) {
+		return 0, "", "", false
+	}
+
+	for j := open + 1; j+size <= len(s); j++ {
+		if s[j:j+size] != delim || isSpaceBefore(s, j) {
+			continue
+		}
+		// Take the last delimiter of a run so ***x*** nests as expected.
+		if j+size < len(s) && s[j+size] == c {
+			continue
+		}
+		if c == '_' && j+size < len(s) && isWordRune(firstRune(s[j+size:])) {
+			continue
+		}
+
+		tag = "em"
+		if size == 2 {
+			tag = "strong"
+		}
+		return j + size, s[open:j], tag, true
+	}
+
+	return 0, "", "", false
+}
+
+// link matches [text](url) opening at i. Parentheses inside the URL must be
+// balanced.
+func link(s string, i int) (end int, text, href string, ok bool) {
+	closeText := strings.IndexByte(s[i+1:], ']')
+	if closeText <= 0 {
+		return 0, "", "", false
+	}
+	text = s[i+1 : i+1+closeText]
+
+	start := i + 1 + closeText + 1
+	if start >= len(s) || s[start] != '(' {
+		return 0, "", "", false
+	}
+
+	parens := 0
+	for j := start + 1; j < len(s); j++ {
+		switch s[j] {
+		case ' ':
+			return 0, "", "", false
+		case '(':
+			parens++
+		case ')':
+			if parens == 0 {
+				href, ok = safeURL(s[start+1 : j])
+				return j + 1, text, href, ok
+			}
+			parens--
+		}
+	}
+
+	return 0, "", "", false
+}
+
+// autolink matches a bare http(s) URL at i, leaving out trailing punctuation
+// and an unbalanced closing parenthesis.
+func autolink(s string, i int) (end int, href string, ok bool) {
+	rest := strings.ToLower(s[i:min(len(s), i+8)])
+	if !strings.HasPrefix(rest, "http://") && !strings.HasPrefix(rest, "https://") {
+		return 0, "", false
+	}
+	if i > 0 && isWordRune(lastRune(s[:i])) {
+		return 0, "", false
+	}
+
+	end = i
+	for end < len(s) && s[end] > ' ' && s[end] != '<' && s[end] != '"' {
+		end++
+	}
+	for end > i {
+		last := s[end-1]
+		if strings.IndexByte(".,;:!?'\"*_", last) >= 0 ||
+			(last == ')' && strings.Count(s[i:end], "(") < strings.Count(s[i:end], ")")) {
+			end--
+			continue
+		}
+		break
+	}
+
+	href, ok = safeURL(s[i:end])
+	return end, href, ok
+}
+
+func safeURL(raw string) (string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		if u.Host == "" {
+			return "", false
+		}
+	case "mailto":
+		if u.Opaque == "" {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+
+	return u.String(), true
+}
+
+func countRun(s string, i int, c byte) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == c {
+		n++
+	}
+	return n
+}
+
+func isSpace(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsSpace(r)
+}
+
+func isSpaceBefore(s string, i int) bool {
+	return unicode.IsSpace(lastRune(s[:i]))
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
+
+func isASCIIPunct(c byte) bool {
+	return c < utf8.RuneSelf && unicode.IsPunct(rune(c)) || strings.IndexByte("`*_[]()#+-.!<>\\", c) >= 0
+}
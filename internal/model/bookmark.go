@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+const MaxCollectionNameLength = 50
+
+type BookmarkCollection struct {
+	ID            int64     `json:"id"`
+	UserID        int64     `json:"user_id"`
+	Name          string    `json:"name"`
+	BookmarkCount int       `json:"bookmark_count"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type BookmarkCollectionCreate struct {
+	Name string `json:"name"`
+}
+
+// BookmarkCreate files a bookmark into a collection. Leaving CollectionID out
+// keeps the bookmark unfiled.
+type BookmarkCreate struct {
+	CollectionID *int64 `json:"collection_id,omitempty"`
+}
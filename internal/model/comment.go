@@ -3,14 +3,28 @@ package model
 import "time"
 
 type Comment struct {
-	ID        int64     `json:"id"`
-	PostID    int64     `json:"post_id"`
-	UserID    int64     `json:"user_id"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
-	Author    *User     `json:"author,omitempty"`
+	ID          int64          `json:"id"`
+	PostID      int64          `json:"post_id"`
+	UserID      int64          `json:"user_id"`
+	ParentID    *int64         `json:"parent_id,omitempty"`
+	Depth       int            `json:"depth"`
+	Content     string         `json:"content"`
+	ContentHTML string         `json:"content_html"`
+	Deleted     bool           `json:"deleted,omitempty"`
+	Hidden      bool           `json:"hidden,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	EditedAt    *time.Time     `json:"edited_at,omitempty"`
+	Author      *User          `json:"author,omitempty"`
+	ReplyCount  int            `json:"reply_count"`
+	Reactions   map[string]int `json:"reactions"`
+	MyReaction  string         `json:"my_reaction,omitempty"`
 }
 
 type CommentCreate struct {
+	Content  string `json:"content"`
+	ParentID int64  `json:"parent_id,omitempty"`
+}
+
+type CommentUpdate struct {
 	Content string `json:"content"`
 }
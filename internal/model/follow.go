@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+type FollowStatus string
+
+const (
+	FollowPending  FollowStatus = "pending"
+	FollowAccepted FollowStatus = "accepted"
+)
+
+// Follow is a one-way relationship. Following a public account is accepted
+// straight away; a private account has to approve the request first.
+type Follow struct {
+	ID         int64        `json:"id"`
+	FollowerID int64        `json:"follower_id"`
+	FolloweeID int64        `json:"followee_id"`
+	Status     FollowStatus `json:"status"`
+	CreatedAt  time.Time    `json:"created_at"`
+	Follower   *UserPublic  `json:"follower,omitempty"`
+}
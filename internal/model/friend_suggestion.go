@@ -0,0 +1,12 @@
+package model
+
+// FriendSuggestion is someone the user is not connected to yet, with the
+// reasons they were suggested. Score weighs mutual friends above shared
+// groups, and a shared emoji avatar only breaks ties.
+type FriendSuggestion struct {
+	User          *UserPublic `json:"user"`
+	MutualFriends int         `json:"mutual_friends"`
+	SharedGroups  int         `json:"shared_groups"`
+	SharedEmoji   bool        `json:"shared_emoji"`
+	Score         int         `json:"score"`
+}
@@ -10,6 +10,33 @@ const (
 	FriendshipBlocked  FriendshipStatus = "blocked"
 )
 
+// FriendshipAction is a transition a user can apply to an existing
+// friendship row. Blocking is allowed from any state, so it is not listed.
+type FriendshipAction string
+
+const (
+	FriendshipAccept   FriendshipAction = "accept"
+	FriendshipDecline  FriendshipAction = "decline"
+	FriendshipCancel   FriendshipAction = "cancel"
+	FriendshipUnfriend FriendshipAction = "unfriend"
+	FriendshipUnblock  FriendshipAction = "unblock"
+)
+
+// CanApply reports whether userID may apply action to the friendship.
+func (f *Friendship) CanApply(action FriendshipAction, userID int64) bool {
+	switch action {
+	case FriendshipAccept, FriendshipDecline:
+		return f.Status == FriendshipPending && f.AddresseeID == userID
+	case FriendshipCancel:
+		return f.Status == FriendshipPending && f.RequesterID == userID
+	case FriendshipUnfriend:
+		return f.Status == FriendshipAccepted && (f.RequesterID == userID || f.AddresseeID == userID)
+	case FriendshipUnblock:
+		return f.Status == FriendshipBlocked && f.BlockedBy != nil && *f.BlockedBy == userID
+	}
+	return false
+}
+
 type Friendship struct {
 	ID          int64            `json:"id"`
 	RequesterID int64            `json:"requester_id"`
@@ -17,6 +44,7 @@ type Friendship struct {
 	Status      FriendshipStatus `json:"status"`
 	CreatedAt   time.Time        `json:"created_at"`
 	UpdatedAt   time.Time        `json:"updated_at"`
+	BlockedBy   *int64           `json:"blocked_by,omitempty"`
 	Requester   *User            `json:"requester,omitempty"`
 	Addressee   *User            `json:"addressee,omitempty"`
 }
@@ -24,13 +24,19 @@ type GroupMember struct {
 }
 
 type GroupPost struct {
-	ID        int64     `json:"id"`
-	GroupID   int64     `json:"group_id"`
-	UserID    int64     `json:"user_id"`
-	Content   string    `json:"content"`
-	MediaURL  string    `json:"media_url"`
-	CreatedAt time.Time `json:"created_at"`
-	Author    *User     `json:"author,omitempty"`
+	ID             int64         `json:"id"`
+	GroupID        int64         `json:"group_id"`
+	UserID         int64         `json:"user_id"`
+	Content        string        `json:"content"`
+	ContentHTML    string        `json:"content_html"`
+	MediaURL       string        `json:"media_url"`
+	ContentWarning string        `json:"content_warning,omitempty"`
+	Sensitive      bool          `json:"sensitive"`
+	Collapsed      bool          `json:"collapsed"`
+	CreatedAt      time.Time     `json:"created_at"`
+	Author         *User         `json:"author,omitempty"`
+	Attachments    []*Attachment `json:"attachments,omitempty"`
+	MutedWords     []string      `json:"muted_words,omitempty"`
 }
 
 type GroupCreate struct {
@@ -41,6 +47,8 @@ type GroupCreate struct {
 type GroupPostCreate struct {
 	Content  string `json:"content"`
 	MediaURL string `json:"media_url"`
+	ContentFlags
+	Attachments []AttachmentCreate `json:"attachments,omitempty"`
 }
 
 type GroupSettings struct {
@@ -1,10 +0,0 @@
-package model
-
-import "time"
-
-type Like struct {
-	ID        int64     `json:"id"`
-	PostID    int64     `json:"post_id"`
-	UserID    int64     `json:"user_id"`
-	CreatedAt time.Time `json:"created_at"`
-}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+type LinkPreviewStatus string
+
+const (
+	LinkPreviewPending LinkPreviewStatus = "pending"
+	LinkPreviewReady   LinkPreviewStatus = "ready"
+	LinkPreviewFailed  LinkPreviewStatus = "failed"
+)
+
+type LinkPreview struct {
+	URL         string            `json:"url"`
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description,omitempty"`
+	ImageURL    string            `json:"image_url,omitempty"`
+	SiteName    string            `json:"site_name,omitempty"`
+	Status      LinkPreviewStatus `json:"-"`
+	FetchedAt   *time.Time        `json:"-"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+const (
+	MaxAttachments   = 4
+	MaxAltTextLength = 1000
+)
+
+type AttachmentTargetType string
+
+const (
+	AttachmentTargetPost      AttachmentTargetType = "post"
+	AttachmentTargetGroupPost AttachmentTargetType = "group_post"
+)
+
+type Media struct {
+	ID          int64     `json:"id"`
+	UserID      int64     `json:"user_id"`
+	URL         string    `json:"url"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Attachment struct {
+	MediaID  int64  `json:"media_id"`
+	URL      string `json:"url"`
+	AltText  string `json:"alt_text,omitempty"`
+	Position int    `json:"position"`
+}
+
+type AttachmentCreate struct {
+	MediaID int64  `json:"media_id"`
+	AltText string `json:"alt_text,omitempty"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+type MentionTargetType string
+
+const (
+	MentionTargetPost      MentionTargetType = "post"
+	MentionTargetComment   MentionTargetType = "comment"
+	MentionTargetGroupPost MentionTargetType = "group_post"
+	MentionTargetMessage   MentionTargetType = "message"
+)
+
+type Mention struct {
+	ID         int64             `json:"id"`
+	UserID     int64             `json:"user_id"`
+	AuthorID   int64             `json:"author_id"`
+	TargetType MentionTargetType `json:"target_type"`
+	TargetID   int64             `json:"target_id"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
@@ -2,28 +2,111 @@ package model
 
 import "time"
 
+// ConversationMemberStatus is where a conversation shows up for a member.
+// A non-friend's first message lands in the recipient's message requests
+// until they accept it; ignoring hides it without telling the sender.
+type ConversationMemberStatus string
+
+const (
+	ConversationMemberAccepted ConversationMemberStatus = "accepted"
+	ConversationMemberRequest  ConversationMemberStatus = "request"
+	ConversationMemberIgnored  ConversationMemberStatus = "ignored"
+)
+
+// ConversationRole is a member's role in a group conversation. Admins can
+// rename the group and manage its members.
+type ConversationRole string
+
+const (
+	ConversationRoleAdmin  ConversationRole = "admin"
+	ConversationRoleMember ConversationRole = "member"
+)
+
+// MessageType separates what members wrote from the system messages that
+// record membership changes in group conversations.
+type MessageType string
+
+const (
+	MessageText   MessageType = "text"
+	MessageSystem MessageType = "system"
+)
+
+// Conversation is either a direct conversation, which has a Participant, or
+// a group conversation, which has a Title and Members. UnreadCount and Muted
+// are the viewer's own.
 type Conversation struct {
-	ID          int64     `json:"id"`
-	CreatedAt   time.Time `json:"created_at"`
-	Members     []*User   `json:"members,omitempty"`
-	Participant *User     `json:"participant,omitempty"`
-	LastMessage *Message  `json:"last_message,omitempty"`
+	ID          int64                 `json:"id"`
+	Title       string                `json:"title,omitempty"`
+	IsGroup     bool                  `json:"is_group"`
+	CreatedAt   time.Time             `json:"created_at"`
+	Members     []*ConversationMember `json:"members,omitempty"`
+	Participant *User                 `json:"participant,omitempty"`
+	LastMessage *Message              `json:"last_message,omitempty"`
+	UnreadCount int                   `json:"unread_count"`
+	Muted       bool                  `json:"muted"`
+}
+
+type ConversationMember struct {
+	UserID  int64                    `json:"user_id"`
+	AddedBy int64                    `json:"added_by,omitempty"`
+	Role    ConversationRole         `json:"role"`
+	Status  ConversationMemberStatus `json:"status"`
+	Muted   bool                     `json:"-"`
+	// LastReadMessageID is the member's read cursor. It is hidden for members
+	// who turned read receipts off.
+	LastReadMessageID int64     `json:"last_read_message_id,omitempty"`
+	JoinedAt          time.Time `json:"joined_at"`
+	User              *User     `json:"user,omitempty"`
 }
 
 type Message struct {
-	ID             int64      `json:"id"`
-	ConversationID int64      `json:"conversation_id"`
-	UserID         int64      `json:"user_id"`
-	Body           string     `json:"body"`
-	CreatedAt      time.Time  `json:"created_at"`
-	ReadAt         *time.Time `json:"read_at,omitempty"`
-	Author         *User      `json:"author,omitempty"`
+	ID             int64          `json:"id"`
+	ConversationID int64          `json:"conversation_id"`
+	UserID         int64          `json:"user_id"`
+	Type           MessageType    `json:"type"`
+	Body           string         `json:"body"`
+	StoryID        *int64         `json:"story_id,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	ReadAt         *time.Time     `json:"read_at,omitempty"`
+	Author         *User          `json:"author,omitempty"`
+	Reactions      map[string]int `json:"reactions"`
+	MyReaction     string         `json:"my_reaction,omitempty"`
 }
 
 type MessageCreate struct {
 	Body string `json:"body"`
 }
 
+// ConversationCreate opens a direct conversation with ParticipantID, or a
+// group conversation when ParticipantIDs is set.
 type ConversationCreate struct {
-	ParticipantID int64 `json:"participant_id"`
+	ParticipantID  int64   `json:"participant_id"`
+	ParticipantIDs []int64 `json:"participant_ids,omitempty"`
+	Title          string  `json:"title,omitempty"`
+}
+
+type ConversationUpdate struct {
+	Title string `json:"title"`
+}
+
+type ConversationMembersAdd struct {
+	UserIDs []int64 `json:"user_ids"`
+}
+
+// MessageRead moves the read cursor. A zero MessageID marks everything read.
+type MessageRead struct {
+	MessageID int64 `json:"message_id"`
+}
+
+// UnreadSummary is the unread badge for messages: unread messages across
+// accepted conversations, how many conversations have them, and pending
+// message requests.
+type UnreadSummary struct {
+	Messages      int `json:"messages"`
+	Conversations int `json:"conversations"`
+	Requests      int `json:"requests"`
+}
+
+type ConversationRoleUpdate struct {
+	Role ConversationRole `json:"role"`
 }
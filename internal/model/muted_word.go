@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+const (
+	MaxMutedWordLength = 100
+	MaxMutedWords      = 500
+)
+
+// MutedWord hides posts and notifications containing Phrase from its owner.
+// A phrase starting with # only matches that hashtag.
+type MutedWord struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	Phrase    string     `json:"phrase"`
+	WholeWord bool       `json:"whole_word"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// MutedWordCreate adds a mute rule. WholeWord defaults to true; a nil
+// ExpiresAt mutes until the rule is removed.
+type MutedWordCreate struct {
+	Phrase    string     `json:"phrase"`
+	WholeWord *bool      `json:"whole_word,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
@@ -10,8 +10,20 @@ const (
 	NotificationComment       NotificationType = "comment"
 	NotificationMessage       NotificationType = "message"
 	NotificationGroupInvite   NotificationType = "group_invite"
+	NotificationMention       NotificationType = "mention"
+	NotificationRepost        NotificationType = "repost"
+	NotificationReply         NotificationType = "reply"
+	NotificationReaction      NotificationType = "reaction"
+	NotificationPollClosed    NotificationType = "poll_closed"
+	NotificationFollow        NotificationType = "follow"
+	NotificationFollowRequest NotificationType = "follow_request"
+	NotificationFollowAccept  NotificationType = "follow_accepted"
 )
 
+// Notification is shown to UserID. ActorID is the user whose action caused
+// it, used to mute notifications from muted users and to fold a burst of
+// messages from one sender into one notification; Count says how many were
+// folded.
 type Notification struct {
 	ID        int64            `json:"id"`
 	UserID    int64            `json:"user_id"`
@@ -19,5 +31,12 @@ type Notification struct {
 	TargetID  int64            `json:"target_id,omitempty"`
 	Message   string           `json:"message"`
 	Read      bool             `json:"read"`
+	Muted     bool             `json:"muted,omitempty"`
+	ActorID   int64            `json:"actor_id,omitempty"`
+	Count     int              `json:"count"`
 	CreatedAt time.Time        `json:"created_at"`
+
+	// Text is the content that triggered the notification, such as a
+	// comment body. It is only used for mute matching and is not stored.
+	Text string `json:"-"`
 }
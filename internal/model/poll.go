@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+const (
+	PollMinOptions   = 2
+	PollMaxOptions   = 10
+	PollOptionMaxLen = 100
+)
+
+// Poll results (vote counts, voter count and, for public polls, voters) are
+// left empty until the viewer has voted or the poll has closed.
+type Poll struct {
+	ID         int64         `json:"id"`
+	PostID     int64         `json:"post_id"`
+	Multiple   bool          `json:"multiple"`
+	Anonymous  bool          `json:"anonymous"`
+	ClosesAt   *time.Time    `json:"closes_at,omitempty"`
+	Closed     bool          `json:"closed"`
+	Options    []*PollOption `json:"options"`
+	Voted      bool          `json:"voted"`
+	MyVotes    []int64       `json:"my_votes,omitempty"`
+	VoterCount *int          `json:"voter_count,omitempty"`
+}
+
+type PollOption struct {
+	ID        int64   `json:"id"`
+	Position  int     `json:"position"`
+	Text      string  `json:"text"`
+	VoteCount *int    `json:"vote_count,omitempty"`
+	Voters    []*User `json:"voters,omitempty"`
+}
+
+type PollCreate struct {
+	Options   []string   `json:"options"`
+	Multiple  bool       `json:"multiple,omitempty"`
+	Anonymous bool       `json:"anonymous,omitempty"`
+	ClosesAt  *time.Time `json:"closes_at,omitempty"`
+}
+
+type PollVote struct {
+	OptionIDs []int64 `json:"option_ids"`
+}
@@ -2,24 +2,96 @@ package model
 
 import "time"
 
+type PostStatus string
+
+const (
+	PostStatusDraft     PostStatus = "draft"
+	PostStatusScheduled PostStatus = "scheduled"
+	PostStatusPublished PostStatus = "published"
+)
+
 type Post struct {
+	ID               int64          `json:"id"`
+	UserID           int64          `json:"user_id"`
+	Content          string         `json:"content"`
+	ContentHTML      string         `json:"content_html"`
+	MediaURL         string         `json:"media_url,omitempty"`
+	ContentWarning   string         `json:"content_warning,omitempty"`
+	Sensitive        bool           `json:"sensitive"`
+	FlagsLocked      bool           `json:"flags_locked,omitempty"`
+	Collapsed        bool           `json:"collapsed"`
+	RepostOfID       *int64         `json:"repost_of_id,omitempty"`
+	QuoteOfID        *int64         `json:"quote_of_id,omitempty"`
+	Status           PostStatus     `json:"status"`
+	PublishAt        *time.Time     `json:"publish_at,omitempty"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	EditedAt         *time.Time     `json:"edited_at,omitempty"`
+	Author           *User          `json:"author,omitempty"`
+	LikeCount        int            `json:"like_count"`
+	Liked            bool           `json:"liked"`
+	Reactions        map[string]int `json:"reactions"`
+	MyReaction       string         `json:"my_reaction,omitempty"`
+	CommentCount     int            `json:"comment_count"`
+	RepostCount      int            `json:"repost_count"`
+	QuoteCount       int            `json:"quote_count"`
+	Reposted         bool           `json:"reposted"`
+	RepostOf         *Post          `json:"repost_of,omitempty"`
+	QuotedPost       *Post          `json:"quoted_post,omitempty"`
+	QuoteUnavailable bool           `json:"quote_unavailable,omitempty"`
+	RepostedBy       []*User        `json:"reposted_by,omitempty"`
+	Poll             *Poll          `json:"poll,omitempty"`
+	Attachments      []*Attachment  `json:"attachments,omitempty"`
+	LinkPreview      *LinkPreview   `json:"link_preview,omitempty"`
+	Bookmarked       bool           `json:"bookmarked"`
+	MutedWords       []string       `json:"muted_words,omitempty"`
+}
+
+const MaxContentWarningLength = 200
+
+// ContentFlags sets a content warning and the sensitive-media flag. Omitted
+// fields are left unchanged; an empty warning removes it.
+type ContentFlags struct {
+	ContentWarning *string `json:"content_warning,omitempty"`
+	Sensitive      *bool   `json:"sensitive,omitempty"`
+}
+
+type PostRevision struct {
 	ID        int64     `json:"id"`
-	UserID    int64     `json:"user_id"`
+	PostID    int64     `json:"post_id"`
+	Revision  int       `json:"revision"`
 	Content   string    `json:"content"`
 	MediaURL  string    `json:"media_url,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Author    *User     `json:"author,omitempty"`
-	LikeCount int       `json:"like_count"`
-	Liked     bool      `json:"liked"`
 }
 
+// PostCreate publishes immediately unless Draft is set or PublishAt schedules
+// the post for later.
 type PostCreate struct {
-	Content  string `json:"content"`
-	MediaURL string `json:"media_url,omitempty"`
+	Content   string `json:"content"`
+	MediaURL  string `json:"media_url,omitempty"`
+	QuoteOfID int64  `json:"quote_of_id,omitempty"`
+	ContentFlags
+	Draft       bool               `json:"draft,omitempty"`
+	PublishAt   *time.Time         `json:"publish_at,omitempty"`
+	Poll        *PollCreate        `json:"poll,omitempty"`
+	Attachments []AttachmentCreate `json:"attachments,omitempty"`
+}
+
+// PostSchedule edits an unpublished post. Without PublishAt it becomes a draft.
+type PostSchedule struct {
+	Content   string     `json:"content"`
+	MediaURL  string     `json:"media_url,omitempty"`
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	ContentFlags
+	Attachments []AttachmentCreate `json:"attachments"`
 }
 
+// PostUpdate replaces the attachments only when Attachments is present; an
+// empty list removes them.
 type PostUpdate struct {
 	Content  string `json:"content"`
 	MediaURL string `json:"media_url,omitempty"`
+	ContentFlags
+	Attachments []AttachmentCreate `json:"attachments"`
 }
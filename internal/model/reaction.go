@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+type ReactionTargetType string
+
+const (
+	ReactionTargetPost    ReactionTargetType = "post"
+	ReactionTargetComment ReactionTargetType = "comment"
+	ReactionTargetMessage ReactionTargetType = "message"
+)
+
+// ReactionLike is the reaction the legacy /likes endpoints map onto.
+const ReactionLike = "like"
+
+type ReactionType struct {
+	ID   string `json:"id"`
+	Char string `json:"char"`
+	Name string `json:"name"`
+}
+
+var PredefinedReactions = []ReactionType{
+	{ID: "like", Char: "👍", Name: "Like"},
+	{ID: "love", Char: "❤️", Name: "Love"},
+	{ID: "haha", Char: "😂", Name: "Haha"},
+	{ID: "wow", Char: "😮", Name: "Wow"},
+	{ID: "sad", Char: "😢", Name: "Sad"},
+	{ID: "angry", Char: "😠", Name: "Angry"},
+	{ID: "fire", Char: "🔥", Name: "Fire"},
+	{ID: "clap", Char: "👏", Name: "Clap"},
+}
+
+func GetReactionByID(id string) *ReactionType {
+	for _, r := range PredefinedReactions {
+		if r.ID == id {
+			return &ReactionType{ID: r.ID, Char: r.Char, Name: r.Name}
+		}
+	}
+	return nil
+}
+
+
+type Reaction struct {
+	ID         int64              `json:"id"`
+	TargetType ReactionTargetType `json:"target_type"`
+	TargetID   int64              `json:"target_id"`
+	UserID     int64              `json:"user_id"`
+	Reaction   string             `json:"reaction"`
+	CreatedAt  time.Time          `json:"created_at"`
+	User       *User              `json:"user,omitempty"`
+}
+
+type ReactionUpdate struct {
+	Reaction string `json:"reaction"`
+}
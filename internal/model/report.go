@@ -22,8 +22,10 @@ type Report struct {
 	TargetID   int64            `json:"target_id"`
 	Reason     string           `json:"reason"`
 	Status     ReportStatus     `json:"status"`
+	RevisionID *int64           `json:"revision_id,omitempty"`
 	CreatedAt  time.Time        `json:"created_at"`
 	Reporter   *User            `json:"reporter,omitempty"`
+	Revision   *PostRevision    `json:"revision,omitempty"`
 }
 
 type ReportCreate struct {
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+type SearchResultType string
+
+const (
+	SearchTypePost      SearchResultType = "post"
+	SearchTypeComment   SearchResultType = "comment"
+	SearchTypeGroupPost SearchResultType = "group_post"
+	SearchTypeGroup     SearchResultType = "group"
+	SearchTypeUser      SearchResultType = "user"
+)
+
+type SearchFilter struct {
+	Query    string
+	Type     SearchResultType
+	AuthorID int64
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+}
+
+type SearchResult struct {
+	Type      SearchResultType `json:"type"`
+	ID        int64            `json:"id"`
+	Snippet   string           `json:"snippet"`
+	Rank      float64          `json:"rank"`
+	CreatedAt time.Time        `json:"created_at"`
+	Post      *Post            `json:"post,omitempty"`
+	Comment   *Comment         `json:"comment,omitempty"`
+	GroupPost *GroupPost       `json:"group_post,omitempty"`
+	Group     *Group           `json:"group,omitempty"`
+	User      *UserPublic      `json:"user,omitempty"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+const MaxStoryLength = 500
+
+type Story struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Content   string    `json:"content,omitempty"`
+	MediaURL  string    `json:"media_url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Author    *User     `json:"author,omitempty"`
+	Viewed    bool      `json:"viewed"`
+	ViewCount *int      `json:"view_count,omitempty"`
+}
+
+type StoryCreate struct {
+	Content  string `json:"content"`
+	MediaURL string `json:"media_url"`
+}
+
+type StoryView struct {
+	UserID   int64     `json:"user_id"`
+	User     *User     `json:"user,omitempty"`
+	ViewedAt time.Time `json:"viewed_at"`
+}
+
+// StoryTrayEntry groups one user's active stories, oldest first.
+type StoryTrayEntry struct {
+	User      *User    `json:"user"`
+	Stories   []*Story `json:"stories"`
+	HasUnseen bool     `json:"has_unseen"`
+}
+
+type StoryReply struct {
+	Body string `json:"body"`
+}
@@ -19,6 +19,8 @@ type User struct {
 	LastSeen          sql.NullTime   `json:"last_seen"`
 	ShowLastSeen      string         `json:"show_last_seen"`
 	AllowMessagesFrom string         `json:"allow_messages_from"`
+	PrivateAccount    bool           `json:"private_account"`
+	ReadReceipts      bool           `json:"read_receipts"`
 	FirebaseUID       sql.NullString `json:"-"`
 	CreatedAt         time.Time      `json:"created_at"`
 }
@@ -42,9 +44,24 @@ type UserProfile struct {
 	EmojiAvatar string `json:"emoji_avatar"`
 }
 
+// UserPrivacySettings updates privacy options. Leaving PrivateAccount or
+// ReadReceipts out keeps the current value.
 type UserPrivacySettings struct {
 	ShowLastSeen      string `json:"show_last_seen"`
 	AllowMessagesFrom string `json:"allow_messages_from"`
+	PrivateAccount    *bool  `json:"private_account,omitempty"`
+	ReadReceipts      *bool  `json:"read_receipts,omitempty"`
+}
+
+const (
+	SensitiveContentHide   = "hide"
+	SensitiveContentExpand = "expand"
+)
+
+// UserPreferences holds display settings. SensitiveContent decides whether
+// posts with a content warning or sensitive media start collapsed.
+type UserPreferences struct {
+	SensitiveContent string `json:"sensitive_content"`
 }
 
 type GoogleLoginRequest struct {
@@ -61,6 +78,11 @@ type UserPublic struct {
 	IsOnline    bool      `json:"is_online"`
 	LastSeen    string    `json:"last_seen,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
+
+	PrivateAccount bool         `json:"private_account"`
+	FollowerCount  int          `json:"follower_count"`
+	FollowingCount int          `json:"following_count"`
+	FollowStatus   FollowStatus `json:"follow_status,omitempty"`
 }
 
 func (u *User) ToPublic(viewerID int64, isFriend bool) *UserPublic {
@@ -73,6 +95,8 @@ func (u *User) ToPublic(viewerID int64, isFriend bool) *UserPublic {
 		EmojiAvatar: u.EmojiAvatar,
 		IsOnline:    u.IsOnline,
 		CreatedAt:   u.CreatedAt,
+
+		PrivateAccount: u.PrivateAccount,
 	}
 
 	showLastSeen := false
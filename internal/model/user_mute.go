@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// UserMuteDurations are the lengths a mute can be set for. Leaving the
+// duration out mutes until the mute is removed.
+var UserMuteDurations = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// UserMute hides MutedUserID's posts, reposts, stories and notifications from
+// UserID without unfriending or unfollowing. The muted user is never told.
+type UserMute struct {
+	ID          int64      `json:"id"`
+	UserID      int64      `json:"user_id"`
+	MutedUserID int64      `json:"muted_user_id"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	MutedUser   *User      `json:"muted_user,omitempty"`
+}
+
+type UserMuteCreate struct {
+	UserID   int64  `json:"user_id"`
+	Duration string `json:"duration,omitempty"`
+}
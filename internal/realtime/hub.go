@@ -0,0 +1,242 @@
+// Package realtime pushes events to connected clients over WebSockets.
+package realtime
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	EventMessage = "message"
+	EventRead    = "read"
+	EventTyping  = "typing"
+	EventPing    = "ping"
+	EventError   = "error"
+
+	// EventResync tells a reconnecting client that events it missed are no
+	// longer held, so it should reload conversations over the REST API.
+	EventResync = "resync"
+)
+
+// Event is what clients receive. Events that can be replayed on reconnect
+// carry an increasing ID; typing indicators and pings have none.
+type Event struct {
+	ID             int64       `json:"id,omitempty"`
+	Type           string      `json:"type"`
+	ConversationID int64       `json:"conversation_id,omitempty"`
+	UserID         int64       `json:"user_id,omitempty"`
+	Data           interface{} `json:"data,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+// Client is one connection. A user has one per open device or tab.
+type Client struct {
+	UserID int64
+	Send   chan *Event
+	closed bool
+	// open is the conversation the client has on screen, if any.
+	open int64
+}
+
+const clientBuffer = 64
+
+type userHistory struct {
+	events []*Event
+	// floor is the highest event ID that may have been dropped. Clients
+	// resuming from below it have missed something.
+	floor int64
+}
+
+// Hub tracks every user's connections and keeps each user's recent events
+// for resume-from-last-event.
+type Hub struct {
+	mu      sync.Mutex
+	clients map[int64]map[*Client]bool
+	history map[int64]*userHistory
+	// floors keeps the floor of each history that aged out entirely, so the
+	// events can go while a resume from before them still resyncs.
+	floors       map[int64]int64
+	nextID       int64
+	startID      int64
+	resumeWindow time.Duration
+	maxHistory   int
+	lastSweep    time.Time
+}
+
+// NewHub creates a hub that can replay up to maxHistory events per user for
+// resumeWindow. Event IDs start from the current time so they keep
+// increasing across restarts and IDs from an earlier run resync.
+func NewHub(resumeWindow time.Duration, maxHistory int) *Hub {
+	start := time.Now().UnixMicro()
+	return &Hub{
+		clients:      make(map[int64]map[*Client]bool),
+		history:      make(map[int64]*userHistory),
+		floors:       make(map[int64]int64),
+		nextID:       start,
+		startID:      start,
+		resumeWindow: resumeWindow,
+		maxHistory:   maxHistory,
+		lastSweep:    time.Now(),
+	}
+}
+
+// Register adds a connection for userID. With a lastEventID it also returns
+// the events the user missed since then, or a single resync event when they
+// are no longer held.
+func (h *Hub) Register(userID, lastEventID int64) (*Client, []*Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	client := &Client{UserID: userID, Send: make(chan *Event, clientBuffer)}
+	if h.clients[userID] == nil {
+		h.clients[userID] = make(map[*Client]bool)
+	}
+	h.clients[userID][client] = true
+
+	if lastEventID == 0 {
+		return client, nil
+	}
+
+	history := h.prune(userID)
+	floor := max(h.startID, h.floors[userID])
+	if history != nil {
+		floor = max(floor, history.floor)
+	}
+	if lastEventID < floor {
+		return client, []*Event{{Type: EventResync, CreatedAt: time.Now()}}
+	}
+
+	var missed []*Event
+	if history != nil {
+		for _, event := range history.events {
+			if event.ID > lastEventID {
+				missed = append(missed, event)
+			}
+		}
+	}
+	return client, missed
+}
+
+func (h *Hub) Unregister(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.close(client)
+}
+
+// close must be called with h.mu held.
+func (h *Hub) close(client *Client) {
+	if client.closed {
+		return
+	}
+	client.closed = true
+	close(client.Send)
+
+	delete(h.clients[client.UserID], client)
+	if len(h.clients[client.UserID]) == 0 {
+		delete(h.clients, client.UserID)
+	}
+}
+
+// Publish assigns the event an ID, records it for each user and pushes it to
+// their connections.
+func (h *Hub) Publish(userIDs []int64, event *Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	event.ID = h.nextID
+	event.CreatedAt = time.Now()
+
+	for _, userID := range userIDs {
+		history := h.prune(userID)
+		if history == nil {
+			history = &userHistory{floor: h.floors[userID]}
+			h.history[userID] = history
+			delete(h.floors, userID)
+		}
+		history.events = append(history.events, event)
+		if len(history.events) > h.maxHistory {
+			history.floor = history.events[0].ID
+			history.events = history.events[1:]
+		}
+	}
+	h.deliver(userIDs, event)
+
+	if time.Since(h.lastSweep) > h.resumeWindow {
+		for userID := range h.history {
+			h.prune(userID)
+		}
+		h.lastSweep = time.Now()
+	}
+}
+
+// Send pushes an event that is not replayed on reconnect, such as a typing
+// indicator.
+func (h *Hub) Send(userIDs []int64, event *Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	event.CreatedAt = time.Now()
+	h.deliver(userIDs, event)
+}
+
+// deliver must be called with h.mu held. A connection that cannot keep up is
+// closed; the client reconnects and resumes from its last event.
+func (h *Hub) deliver(userIDs []int64, event *Event) {
+	for _, userID := range userIDs {
+		for client := range h.clients[userID] {
+			select {
+			case client.Send <- event:
+			default:
+				h.close(client)
+			}
+		}
+	}
+}
+
+// prune drops the user's events older than the resume window and returns
+// their history, or nil if they have none left. It must be called with h.mu
+// held.
+func (h *Hub) prune(userID int64) *userHistory {
+	history := h.history[userID]
+	if history == nil {
+		return nil
+	}
+
+	cutoff := time.Now().Add(-h.resumeWindow)
+	kept := 0
+	for kept < len(history.events) && history.events[kept].CreatedAt.Before(cutoff) {
+		history.floor = history.events[kept].ID
+		kept++
+	}
+	history.events = history.events[kept:]
+
+	// Once every event has aged out only the floor is worth keeping.
+	if len(history.events) == 0 {
+		h.floors[userID] = history.floor
+		delete(h.history, userID)
+		return nil
+	}
+	return history
+}
+
+// SetOpen records which conversation the client is showing; 0 means none.
+func (h *Hub) SetOpen(client *Client, conversationID int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	client.open = conversationID
+}
+
+// IsViewing reports whether any of the user's connections has the
+// conversation open.
+func (h *Hub) IsViewing(userID, conversationID int64) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for client := range h.clients[userID] {
+		if client.open == conversationID {
+			return true
+		}
+	}
+	return false
+}
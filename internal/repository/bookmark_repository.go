@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"socialnet/internal/model"
+)
+
+type BookmarkRepository struct {
+	db *sql.DB
+}
+
+func NewBookmarkRepository(db *sql.DB) *BookmarkRepository {
+	return &BookmarkRepository{db: db}
+}
+
+// Set bookmarks a post, moving an existing bookmark to the given collection.
+func (r *BookmarkRepository) Set(userID, postID int64, collectionID *int64) error {
+	query := `INSERT INTO bookmarks (user_id, post_id, collection_id) VALUES (?, ?, ?)
+			  ON CONFLICT(user_id, post_id) DO UPDATE SET collection_id = excluded.collection_id`
+	_, err := r.db.Exec(query, userID, postID, collectionID)
+	return err
+}
+
+func (r *BookmarkRepository) Delete(userID, postID int64) error {
+	result, err := r.db.Exec(`DELETE FROM bookmarks WHERE user_id = ? AND post_id = ?`, userID, postID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.New("bookmark not found")
+	}
+	return nil
+}
+
+func (r *BookmarkRepository) IsBookmarked(userID, postID int64) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM bookmarks WHERE user_id = ? AND post_id = ?)`
+	var exists bool
+	err := r.db.QueryRow(query, userID, postID).Scan(&exists)
+	return exists, err
+}
+
+// GetPostIDs lists bookmarked posts, newest bookmark first. A nil collection
+// lists every bookmark.
+func (r *BookmarkRepository) GetPostIDs(userID int64, collectionID *int64, limit, offset int) ([]int64, error) {
+	query := `SELECT post_id FROM bookmarks
+			  WHERE user_id = ? AND (? IS NULL OR collection_id = ?)
+			  ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`
+	rows, err := r.db.Query(query, userID, collectionID, collectionID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// DeleteInvisible drops the user's bookmarks on posts they can no longer see.
+func (r *BookmarkRepository) DeleteInvisible(userID int64) error {
+	query := `DELETE FROM bookmarks WHERE user_id = ? AND post_id NOT IN (
+			  SELECT p.id FROM posts p WHERE ` + visiblePostCondition + `)`
+	_, err := r.db.Exec(query, userID, userID, userID, userID, userID, userID, userID)
+	return err
+}
+
+func (r *BookmarkRepository) CreateCollection(userID int64, name string) (int64, error) {
+	result, err := r.db.Exec(`INSERT INTO bookmark_collections (user_id, name) VALUES (?, ?)`, userID, name)
+	if err != nil {
+		return 0, errors.New("collection already exists")
+	}
+	return result.LastInsertId()
+}
+
+func (r *BookmarkRepository) GetCollection(id int64) (*model.BookmarkCollection, error) {
+	query := `SELECT c.id, c.user_id, c.name, c.created_at,
+			  (SELECT COUNT(*) FROM bookmarks b WHERE b.collection_id = c.id)
+			  FROM bookmark_collections c WHERE c.id = ?`
+	collection := &model.BookmarkCollection{}
+	err := r.db.QueryRow(query, id).Scan(&collection.ID, &collection.UserID, &collection.Name,
+		&collection.CreatedAt, &collection.BookmarkCount)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("collection not found")
+	}
+	return collection, err
+}
+
+func (r *BookmarkRepository) GetCollections(userID int64) ([]*model.BookmarkCollection, error) {
+	query := `SELECT c.id, c.user_id, c.name, c.created_at,
+			  (SELECT COUNT(*) FROM bookmarks b WHERE b.collection_id = c.id)
+			  FROM bookmark_collections c WHERE c.user_id = ? ORDER BY c.name COLLATE NOCASE ASC`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var collections []*model.BookmarkCollection
+	for rows.Next() {
+		collection := &model.BookmarkCollection{}
+		if err := rows.Scan(&collection.ID, &collection.UserID, &collection.Name,
+			&collection.CreatedAt, &collection.BookmarkCount); err != nil {
+			return nil, err
+		}
+		collections = append(collections, collection)
+	}
+	return collections, rows.Err()
+}
+
+func (r *BookmarkRepository) RenameCollection(id int64, name string) error {
+	if _, err := r.db.Exec(`UPDATE bookmark_collections SET name = ? WHERE id = ?`, name, id); err != nil {
+		return errors.New("collection already exists")
+	}
+	return nil
+}
+
+func (r *BookmarkRepository) DeleteCollection(id int64) error {
+	_, err := r.db.Exec(`DELETE FROM bookmark_collections WHERE id = ?`, id)
+	return err
+}
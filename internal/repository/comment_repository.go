@@ -6,6 +6,8 @@ import (
 	"socialnet/internal/model"
 )
 
+const commentColumns = `id, post_id, user_id, parent_id, COALESCE(depth, 0), content, created_at, edited_at, deleted_at`
+
 type CommentRepository struct {
 	db *sql.DB
 }
@@ -15,8 +17,8 @@ func NewCommentRepository(db *sql.DB) *CommentRepository {
 }
 
 func (r *CommentRepository) Create(comment *model.Comment) (int64, error) {
-	query := `INSERT INTO comments (post_id, user_id, content) VALUES (?, ?, ?)`
-	result, err := r.db.Exec(query, comment.PostID, comment.UserID, comment.Content)
+	query := `INSERT INTO comments (post_id, user_id, parent_id, depth, content) VALUES (?, ?, ?, ?, ?)`
+	result, err := r.db.Exec(query, comment.PostID, comment.UserID, comment.ParentID, comment.Depth, comment.Content)
 	if err != nil {
 		return 0, err
 	}
@@ -24,11 +26,8 @@ func (r *CommentRepository) Create(comment *model.Comment) (int64, error) {
 }
 
 func (r *CommentRepository) GetByID(id int64) (*model.Comment, error) {
-	query := `SELECT id, post_id, user_id, content, created_at FROM comments WHERE id = ?`
-	comment := &model.Comment{}
-	err := r.db.QueryRow(query, id).Scan(
-		&comment.ID, &comment.PostID, &comment.UserID, &comment.Content, &comment.CreatedAt,
-	)
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE id = ?`
+	comment, err := scanComment(r.db.QueryRow(query, id))
 	if err == sql.ErrNoRows {
 		return nil, errors.New("comment not found")
 	}
@@ -36,8 +35,8 @@ func (r *CommentRepository) GetByID(id int64) (*model.Comment, error) {
 }
 
 func (r *CommentRepository) GetByPostID(postID int64) ([]*model.Comment, error) {
-	query := `SELECT id, post_id, user_id, content, created_at 
-			  FROM comments WHERE post_id = ? ORDER BY created_at ASC`
+	query := `SELECT ` + commentColumns + ` 
+			  FROM comments WHERE post_id = ? ORDER BY created_at ASC, id ASC`
 	rows, err := r.db.Query(query, postID)
 	if err != nil {
 		return nil, err
@@ -46,8 +45,7 @@ func (r *CommentRepository) GetByPostID(postID int64) ([]*model.Comment, error)
 
 	var comments []*model.Comment
 	for rows.Next() {
-		comment := &model.Comment{}
-		err := rows.Scan(&comment.ID, &comment.PostID, &comment.UserID, &comment.Content, &comment.CreatedAt)
+		comment, err := scanComment(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -56,8 +54,67 @@ func (r *CommentRepository) GetByPostID(postID int64) ([]*model.Comment, error)
 	return comments, rows.Err()
 }
 
+func (r *CommentRepository) GetCountByPostID(postID int64) (int, error) {
+	query := `SELECT COUNT(*) FROM comments WHERE post_id = ? AND deleted_at IS NULL`
+	var count int
+	err := r.db.QueryRow(query, postID).Scan(&count)
+	return count, err
+}
+
+func (r *CommentRepository) HasReplies(id int64) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM comments WHERE parent_id = ?)`
+	var exists bool
+	err := r.db.QueryRow(query, id).Scan(&exists)
+	return exists, err
+}
+
+func (r *CommentRepository) Update(id int64, content string) error {
+	query := `UPDATE comments SET content = ?, edited_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL`
+	result, err := r.db.Exec(query, content, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.New("comment not found")
+	}
+	return nil
+}
+
+// SoftDelete blanks a comment but keeps its row so replies stay attached.
+func (r *CommentRepository) SoftDelete(id int64) error {
+	query := `UPDATE comments SET content = '', deleted_at = CURRENT_TIMESTAMP WHERE id = ?`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+// Delete removes a comment and every reply beneath it.
 func (r *CommentRepository) Delete(id int64) error {
-	query := `DELETE FROM comments WHERE id = ?`
+	query := `WITH RECURSIVE thread(id) AS (
+				SELECT id FROM comments WHERE id = ?
+				UNION ALL
+				SELECT c.id FROM comments c INNER JOIN thread t ON c.parent_id = t.id
+			  )
+			  DELETE FROM comments WHERE id IN (SELECT id FROM thread)`
 	_, err := r.db.Exec(query, id)
 	return err
 }
+
+func scanComment(row rowScanner) (*model.Comment, error) {
+	comment := &model.Comment{}
+	var parentID sql.NullInt64
+	var editedAt, deletedAt sql.NullTime
+	err := row.Scan(&comment.ID, &comment.PostID, &comment.UserID, &parentID, &comment.Depth,
+		&comment.Content, &comment.CreatedAt, &editedAt, &deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	if parentID.Valid {
+		comment.ParentID = &parentID.Int64
+	}
+	if editedAt.Valid {
+		comment.EditedAt = &editedAt.Time
+	}
+	comment.Deleted = deletedAt.Valid
+	return comment, nil
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"socialnet/internal/model"
+)
+
+type FollowRepository struct {
+	db *sql.DB
+}
+
+func NewFollowRepository(db *sql.DB) *FollowRepository {
+	return &FollowRepository{db: db}
+}
+
+func (r *FollowRepository) Create(followerID, followeeID int64, status model.FollowStatus) (int64, error) {
+	query := `INSERT INTO follows (follower_id, followee_id, status) VALUES (?, ?, ?)`
+	result, err := r.db.Exec(query, followerID, followeeID, status)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+func (r *FollowRepository) GetByID(id int64) (*model.Follow, error) {
+	query := `SELECT id, follower_id, followee_id, status, created_at FROM follows WHERE id = ?`
+	follow := &model.Follow{}
+	err := r.db.QueryRow(query, id).Scan(&follow.ID, &follow.FollowerID, &follow.FolloweeID,
+		&follow.Status, &follow.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("follow request not found")
+	}
+	return follow, err
+}
+
+// Get returns the follow from followerID to followeeID, or nil if there is
+// none.
+func (r *FollowRepository) Get(followerID, followeeID int64) (*model.Follow, error) {
+	query := `SELECT id, follower_id, followee_id, status, created_at FROM follows
+			  WHERE follower_id = ? AND followee_id = ?`
+	follow := &model.Follow{}
+	err := r.db.QueryRow(query, followerID, followeeID).Scan(&follow.ID, &follow.FollowerID, &follow.FolloweeID,
+		&follow.Status, &follow.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return follow, err
+}
+
+func (r *FollowRepository) Accept(id int64) error {
+	_, err := r.db.Exec(`UPDATE follows SET status = 'accepted' WHERE id = ?`, id)
+	return err
+}
+
+// AcceptAllPending approves every pending request to the user and returns the
+// followers that were waiting.
+func (r *FollowRepository) AcceptAllPending(followeeID int64) ([]int64, error) {
+	rows, err := r.db.Query(`SELECT follower_id FROM follows WHERE followee_id = ? AND status = 'pending'`, followeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	_, err = r.db.Exec(`UPDATE follows SET status = 'accepted' WHERE followee_id = ? AND status = 'pending'`, followeeID)
+	return ids, err
+}
+
+func (r *FollowRepository) Delete(id int64) error {
+	_, err := r.db.Exec(`DELETE FROM follows WHERE id = ?`, id)
+	return err
+}
+
+// DeleteBetween removes follows in either direction between two users.
+func (r *FollowRepository) DeleteBetween(userID1, userID2 int64) error {
+	_, err := r.db.Exec(`DELETE FROM follows
+		WHERE (follower_id = ? AND followee_id = ?) OR (follower_id = ? AND followee_id = ?)`,
+		userID1, userID2, userID2, userID1)
+	return err
+}
+
+func (r *FollowRepository) GetPendingRequests(followeeID int64) ([]*model.Follow, error) {
+	query := `SELECT id, follower_id, followee_id, status, created_at FROM follows
+			  WHERE followee_id = ? AND status = 'pending' ORDER BY created_at DESC, id DESC`
+	rows, err := r.db.Query(query, followeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var follows []*model.Follow
+	for rows.Next() {
+		follow := &model.Follow{}
+		if err := rows.Scan(&follow.ID, &follow.FollowerID, &follow.FolloweeID,
+			&follow.Status, &follow.CreatedAt); err != nil {
+			return nil, err
+		}
+		follows = append(follows, follow)
+	}
+	return follows, rows.Err()
+}
+
+// GetFollowers lists the accounts following the user, most recent first.
+func (r *FollowRepository) GetFollowers(userID int64, limit, offset int) ([]*model.User, error) {
+	query := `SELECT ` + followUserColumns + ` FROM follows f
+			  INNER JOIN users u ON u.id = f.follower_id
+			  WHERE f.followee_id = ? AND f.status = 'accepted'
+			  ORDER BY f.created_at DESC, f.id DESC LIMIT ? OFFSET ?`
+	return r.queryUsers(query, userID, limit, offset)
+}
+
+// GetFollowing lists the accounts the user follows, most recent first.
+func (r *FollowRepository) GetFollowing(userID int64, limit, offset int) ([]*model.User, error) {
+	query := `SELECT ` + followUserColumns + ` FROM follows f
+			  INNER JOIN users u ON u.id = f.followee_id
+			  WHERE f.follower_id = ? AND f.status = 'accepted'
+			  ORDER BY f.created_at DESC, f.id DESC LIMIT ? OFFSET ?`
+	return r.queryUsers(query, userID, limit, offset)
+}
+
+func (r *FollowRepository) GetCounts(userID int64) (followers int, following int, err error) {
+	query := `SELECT COALESCE(SUM(followee_id = ?), 0), COALESCE(SUM(follower_id = ?), 0)
+			  FROM follows WHERE (followee_id = ? OR follower_id = ?) AND status = 'accepted'`
+	err = r.db.QueryRow(query, userID, userID, userID, userID).Scan(&followers, &following)
+	return followers, following, err
+}
+
+const followUserColumns = `u.id, u.username, COALESCE(u.full_name, ''), COALESCE(u.bio, ''), COALESCE(u.avatar_url, ''),
+			  COALESCE(u.emoji_avatar, ''), COALESCE(u.private_account, TRUE), u.created_at`
+
+func (r *FollowRepository) queryUsers(query string, userID int64, limit, offset int) ([]*model.User, error) {
+	rows, err := r.db.Query(query, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*model.User
+	for rows.Next() {
+		user := &model.User{}
+		if err := rows.Scan(&user.ID, &user.Username, &user.FullName, &user.Bio, &user.AvatarURL,
+			&user.EmojiAvatar, &user.PrivateAccount, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
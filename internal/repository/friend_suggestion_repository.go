@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"database/sql"
+	"socialnet/internal/model"
+)
+
+// maxStoredSuggestions caps how many suggestions are kept per user.
+const maxStoredSuggestions = 50
+
+// FriendSuggestionRepository keeps precomputed suggestions in
+// friend_suggestions. Triggers on friendships, group_members and users mark
+// the affected users stale in suggestion_refresh, and Refresh recomputes
+// one user at a time, so the work follows what changed rather than the
+// size of the graph.
+type FriendSuggestionRepository struct {
+	db *sql.DB
+}
+
+func NewFriendSuggestionRepository(db *sql.DB) *FriendSuggestionRepository {
+	return &FriendSuggestionRepository{db: db}
+}
+
+// GetStale returns up to limit users whose suggestions need recomputing.
+func (r *FriendSuggestionRepository) GetStale(limit int) ([]int64, error) {
+	rows, err := r.db.Query(`SELECT user_id FROM suggestion_refresh WHERE stale = TRUE
+		ORDER BY refreshed_at IS NOT NULL, refreshed_at LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// NeverRefreshed reports whether the user's suggestions are waiting for their
+// first computation.
+func (r *FriendSuggestionRepository) NeverRefreshed(userID int64) (bool, error) {
+	var pending bool
+	err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM suggestion_refresh
+		WHERE user_id = ? AND refreshed_at IS NULL)`, userID).Scan(&pending)
+	return pending, err
+}
+
+// Refresh recomputes the user's suggestions from friends of friends and
+// fellow group members. The stale flag is cleared first in the same
+// transaction, so a change committed while this runs marks the user again.
+func (r *FriendSuggestionRepository) Refresh(userID int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE suggestion_refresh SET stale = FALSE, refreshed_at = CURRENT_TIMESTAMP
+		WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM friend_suggestions WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+
+	query := `WITH friends(id) AS (
+				SELECT addressee_id FROM friendships WHERE requester_id = ? AND status = 'accepted'
+				UNION
+				SELECT requester_id FROM friendships WHERE addressee_id = ? AND status = 'accepted'
+			  ),
+			  reached(id, via) AS (
+				SELECT f.addressee_id, 'friend' FROM friends
+				INNER JOIN friendships f ON f.requester_id = friends.id AND f.status = 'accepted'
+				UNION ALL
+				SELECT f.requester_id, 'friend' FROM friends
+				INNER JOIN friendships f ON f.addressee_id = friends.id AND f.status = 'accepted'
+				UNION ALL
+				SELECT other.user_id, 'group' FROM group_members mine
+				INNER JOIN group_members other ON other.group_id = mine.group_id
+				WHERE mine.user_id = ?
+			  )
+			  INSERT INTO friend_suggestions (user_id, suggested_user_id, mutual_friends, shared_groups, shared_emoji, score)
+			  SELECT user_id, suggested_user_id, mutual_friends, shared_groups, shared_emoji,
+				mutual_friends * 3 + shared_groups * 2 + shared_emoji AS score
+			  FROM (
+				SELECT me.id AS user_id, u.id AS suggested_user_id,
+					SUM(r.via = 'friend') AS mutual_friends, SUM(r.via = 'group') AS shared_groups,
+					COALESCE(me.emoji_avatar, '') != '' AND u.emoji_avatar IS me.emoji_avatar AS shared_emoji
+				FROM reached r
+				INNER JOIN users u ON u.id = r.id
+				INNER JOIN users me ON me.id = ?
+				WHERE u.id != me.id
+				AND NOT EXISTS (SELECT 1 FROM friendships x
+					WHERE (x.requester_id = me.id AND x.addressee_id = u.id)
+					OR (x.requester_id = u.id AND x.addressee_id = me.id))
+				AND NOT EXISTS (SELECT 1 FROM suggestion_dismissals d
+					WHERE d.user_id = me.id AND d.dismissed_user_id = u.id)
+				GROUP BY u.id
+			  )
+			  ORDER BY score DESC, mutual_friends DESC, suggested_user_id
+			  LIMIT ?`
+	if _, err := tx.Exec(query, userID, userID, userID, userID, maxStoredSuggestions); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetForUser lists the user's suggestions, best first. Pairs that have since
+// become connected, blocked or dismissed are skipped even before the next
+// refresh.
+func (r *FriendSuggestionRepository) GetForUser(userID int64, limit int) ([]*model.FriendSuggestion, error) {
+	query := `SELECT s.mutual_friends, s.shared_groups, s.shared_emoji, s.score, ` + followUserColumns + `
+			  FROM friend_suggestions s
+			  INNER JOIN users u ON u.id = s.suggested_user_id
+			  WHERE s.user_id = ?
+			  AND NOT EXISTS (SELECT 1 FROM friendships x
+				WHERE (x.requester_id = s.user_id AND x.addressee_id = u.id)
+				OR (x.requester_id = u.id AND x.addressee_id = s.user_id))
+			  AND NOT EXISTS (SELECT 1 FROM suggestion_dismissals d
+				WHERE d.user_id = s.user_id AND d.dismissed_user_id = u.id)
+			  ORDER BY s.score DESC, s.mutual_friends DESC, s.suggested_user_id
+			  LIMIT ?`
+	rows, err := r.db.Query(query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suggestions []*model.FriendSuggestion
+	for rows.Next() {
+		suggestion := &model.FriendSuggestion{}
+		user := &model.User{}
+		err := rows.Scan(&suggestion.MutualFriends, &suggestion.SharedGroups, &suggestion.SharedEmoji,
+			&suggestion.Score, &user.ID, &user.Username, &user.FullName, &user.Bio, &user.AvatarURL,
+			&user.EmojiAvatar, &user.PrivateAccount, &user.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		suggestion.User = user.ToPublic(userID, false)
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, rows.Err()
+}
+
+// Dismiss stops suggesting dismissedID to userID for good.
+func (r *FriendSuggestionRepository) Dismiss(userID, dismissedID int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT OR IGNORE INTO suggestion_dismissals (user_id, dismissed_user_id) VALUES (?, ?)`,
+		userID, dismissedID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM friend_suggestions WHERE user_id = ? AND suggested_user_id = ?`,
+		userID, dismissedID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -5,6 +5,8 @@ import (
 	"socialnet/internal/model"
 )
 
+const friendshipColumns = `id, requester_id, addressee_id, status, blocked_by, created_at, updated_at`
+
 type FriendshipRepository struct {
 	db *sql.DB
 }
@@ -13,6 +15,13 @@ func NewFriendshipRepository(db *sql.DB) *FriendshipRepository {
 	return &FriendshipRepository{db: db}
 }
 
+func scanFriendship(row rowScanner) (*model.Friendship, error) {
+	friendship := &model.Friendship{}
+	err := row.Scan(&friendship.ID, &friendship.RequesterID, &friendship.AddresseeID,
+		&friendship.Status, &friendship.BlockedBy, &friendship.CreatedAt, &friendship.UpdatedAt)
+	return friendship, err
+}
+
 func (r *FriendshipRepository) CreateRequest(requesterID, addresseeID int64) (int64, error) {
 	query := `INSERT INTO friendships (requester_id, addressee_id, status) VALUES (?, ?, 'pending')`
 	result, err := r.db.Exec(query, requesterID, addresseeID)
@@ -28,15 +37,39 @@ func (r *FriendshipRepository) UpdateStatus(id int64, status model.FriendshipSta
 	return err
 }
 
+// Block records blockerID's block on blockedID, replacing any request or
+// friendship between them. Each direction is its own row, with the blocker as
+// requester, so a block made by the other user is left in place.
+func (r *FriendshipRepository) Block(blockerID, blockedID int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM friendships
+		WHERE ((requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?))
+		AND (status != 'blocked' OR blocked_by = ?)`,
+		blockerID, blockedID, blockedID, blockerID, blockerID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`INSERT INTO friendships (requester_id, addressee_id, status, blocked_by)
+		VALUES (?, ?, 'blocked', ?)`, blockerID, blockedID, blockerID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *FriendshipRepository) Delete(id int64) error {
+	_, err := r.db.Exec(`DELETE FROM friendships WHERE id = ?`, id)
+	return err
+}
+
 func (r *FriendshipRepository) GetByID(id int64) (*model.Friendship, error) {
-	query := `SELECT id, requester_id, addressee_id, status, created_at, updated_at 
-			  FROM friendships WHERE id = ?`
-	friendship := &model.Friendship{}
-	err := r.db.QueryRow(query, id).Scan(
-		&friendship.ID, &friendship.RequesterID, &friendship.AddresseeID,
-		&friendship.Status, &friendship.CreatedAt, &friendship.UpdatedAt,
-	)
-	return friendship, err
+	query := `SELECT ` + friendshipColumns + ` FROM friendships WHERE id = ?`
+	return scanFriendship(r.db.QueryRow(query, id))
 }
 
 func (r *FriendshipRepository) GetFriends(userID int64) ([]*model.User, error) {
@@ -45,7 +78,40 @@ func (r *FriendshipRepository) GetFriends(userID int64) ([]*model.User, error) {
 			  INNER JOIN friendships f ON (f.requester_id = u.id OR f.addressee_id = u.id)
 			  WHERE (f.requester_id = ? OR f.addressee_id = ?) 
 			  AND f.status = 'accepted' AND u.id != ?`
-	rows, err := r.db.Query(query, userID, userID, userID)
+	return r.queryUsers(query, userID, userID, userID)
+}
+
+// GetMutualFriends lists the users who are friends with both userID1 and
+// userID2.
+func (r *FriendshipRepository) GetMutualFriends(userID1, userID2 int64) ([]*model.User, error) {
+	query := `SELECT u.id, u.email, u.username, u.full_name, u.bio, u.avatar_url, u.is_admin, u.created_at
+			  FROM users u
+			  WHERE u.id IN (
+				SELECT addressee_id FROM friendships WHERE requester_id = ? AND status = 'accepted'
+				UNION
+				SELECT requester_id FROM friendships WHERE addressee_id = ? AND status = 'accepted'
+			  )
+			  AND u.id IN (
+				SELECT addressee_id FROM friendships WHERE requester_id = ? AND status = 'accepted'
+				UNION
+				SELECT requester_id FROM friendships WHERE addressee_id = ? AND status = 'accepted'
+			  )
+			  ORDER BY u.username`
+	return r.queryUsers(query, userID1, userID1, userID2, userID2)
+}
+
+// GetBlockedUsers lists the users that userID has blocked.
+func (r *FriendshipRepository) GetBlockedUsers(userID int64) ([]*model.User, error) {
+	query := `SELECT u.id, u.email, u.username, u.full_name, u.bio, u.avatar_url, u.is_admin, u.created_at
+			  FROM users u
+			  INNER JOIN friendships f ON f.addressee_id = u.id
+			  WHERE f.requester_id = ? AND f.status = 'blocked'
+			  ORDER BY f.updated_at DESC`
+	return r.queryUsers(query, userID)
+}
+
+func (r *FriendshipRepository) queryUsers(query string, args ...interface{}) ([]*model.User, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -65,8 +131,18 @@ func (r *FriendshipRepository) GetFriends(userID int64) ([]*model.User, error) {
 }
 
 func (r *FriendshipRepository) GetPendingRequests(userID int64) ([]*model.Friendship, error) {
-	query := `SELECT id, requester_id, addressee_id, status, created_at, updated_at 
+	query := `SELECT ` + friendshipColumns + ` 
 			  FROM friendships WHERE addressee_id = ? AND status = 'pending' ORDER BY created_at DESC`
+	return r.queryFriendships(query, userID)
+}
+
+func (r *FriendshipRepository) GetOutgoingRequests(userID int64) ([]*model.Friendship, error) {
+	query := `SELECT ` + friendshipColumns + ` 
+			  FROM friendships WHERE requester_id = ? AND status = 'pending' ORDER BY created_at DESC`
+	return r.queryFriendships(query, userID)
+}
+
+func (r *FriendshipRepository) queryFriendships(query string, userID int64) ([]*model.Friendship, error) {
 	rows, err := r.db.Query(query, userID)
 	if err != nil {
 		return nil, err
@@ -75,9 +151,7 @@ func (r *FriendshipRepository) GetPendingRequests(userID int64) ([]*model.Friend
 
 	var friendships []*model.Friendship
 	for rows.Next() {
-		friendship := &model.Friendship{}
-		err := rows.Scan(&friendship.ID, &friendship.RequesterID, &friendship.AddresseeID,
-			&friendship.Status, &friendship.CreatedAt, &friendship.UpdatedAt)
+		friendship, err := scanFriendship(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -98,16 +172,56 @@ func (r *FriendshipRepository) AreFriends(userID1, userID2 int64) (bool, error)
 }
 
 func (r *FriendshipRepository) GetFriendship(userID1, userID2 int64) (*model.Friendship, error) {
-	query := `SELECT id, requester_id, addressee_id, status, created_at, updated_at 
+	query := `SELECT ` + friendshipColumns + ` 
 			  FROM friendships 
 			  WHERE (requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)`
-	friendship := &model.Friendship{}
-	err := r.db.QueryRow(query, userID1, userID2, userID2, userID1).Scan(
-		&friendship.ID, &friendship.RequesterID, &friendship.AddresseeID,
-		&friendship.Status, &friendship.CreatedAt, &friendship.UpdatedAt,
-	)
+	friendship, err := scanFriendship(r.db.QueryRow(query, userID1, userID2, userID2, userID1))
+	if err != nil {
+		return nil, err
+	}
+	return friendship, nil
+}
+
+// GetBlockedBy returns blockerID's block on blockedID, ignoring any block in
+// the other direction.
+func (r *FriendshipRepository) GetBlockedBy(blockerID, blockedID int64) (*model.Friendship, error) {
+	query := `SELECT ` + friendshipColumns + ` 
+			  FROM friendships WHERE requester_id = ? AND addressee_id = ? AND status = 'blocked'`
+	friendship, err := scanFriendship(r.db.QueryRow(query, blockerID, blockedID))
 	if err != nil {
 		return nil, err
 	}
 	return friendship, nil
 }
+
+// GetBlockedIDs returns the users blocked by or blocking userID.
+func (r *FriendshipRepository) GetBlockedIDs(userID int64) ([]int64, error) {
+	query := `SELECT DISTINCT CASE WHEN requester_id = ? THEN addressee_id ELSE requester_id END
+			  FROM friendships WHERE (requester_id = ? OR addressee_id = ?) AND status = 'blocked'`
+	rows, err := r.db.Query(query, userID, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *FriendshipRepository) IsBlocked(userID1, userID2 int64) (bool, error) {
+	query := `SELECT EXISTS(
+		SELECT 1 FROM friendships 
+		WHERE ((requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?))
+		AND status = 'blocked'
+	)`
+	var exists bool
+	err := r.db.QueryRow(query, userID1, userID2, userID2, userID1).Scan(&exists)
+	return exists, err
+}
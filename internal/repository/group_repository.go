@@ -97,8 +97,8 @@ func (r *GroupRepository) GetMembers(groupID int64) ([]*model.GroupMember, error
 }
 
 func (r *GroupRepository) CreatePost(post *model.GroupPost) (int64, error) {
-	query := `INSERT INTO group_posts (group_id, user_id, content, media_url) VALUES (?, ?, ?, ?)`
-	result, err := r.db.Exec(query, post.GroupID, post.UserID, post.Content, post.MediaURL)
+	query := `INSERT INTO group_posts (group_id, user_id, content, media_url, content_warning, sensitive) VALUES (?, ?, ?, ?, ?, ?)`
+	result, err := r.db.Exec(query, post.GroupID, post.UserID, post.Content, post.MediaURL, post.ContentWarning, post.Sensitive)
 	if err != nil {
 		return 0, err
 	}
@@ -106,10 +106,12 @@ func (r *GroupRepository) CreatePost(post *model.GroupPost) (int64, error) {
 }
 
 func (r *GroupRepository) GetPostByID(id int64) (*model.GroupPost, error) {
-	query := `SELECT id, group_id, user_id, content, COALESCE(media_url, ''), created_at FROM group_posts WHERE id = ?`
+	query := `SELECT id, group_id, user_id, content, COALESCE(media_url, ''), COALESCE(content_warning, ''),
+			  COALESCE(sensitive, FALSE), created_at FROM group_posts WHERE id = ?`
 	post := &model.GroupPost{}
 	err := r.db.QueryRow(query, id).Scan(
-		&post.ID, &post.GroupID, &post.UserID, &post.Content, &post.MediaURL, &post.CreatedAt,
+		&post.ID, &post.GroupID, &post.UserID, &post.Content, &post.MediaURL, &post.ContentWarning,
+		&post.Sensitive, &post.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, errors.New("group post not found")
@@ -118,7 +120,8 @@ func (r *GroupRepository) GetPostByID(id int64) (*model.GroupPost, error) {
 }
 
 func (r *GroupRepository) GetPosts(groupID int64, limit int) ([]*model.GroupPost, error) {
-	query := `SELECT id, group_id, user_id, content, COALESCE(media_url, ''), created_at 
+	query := `SELECT id, group_id, user_id, content, COALESCE(media_url, ''), COALESCE(content_warning, ''),
+			  COALESCE(sensitive, FALSE), created_at 
 			  FROM group_posts WHERE group_id = ? ORDER BY created_at DESC LIMIT ?`
 	rows, err := r.db.Query(query, groupID, limit)
 	if err != nil {
@@ -129,7 +132,8 @@ func (r *GroupRepository) GetPosts(groupID int64, limit int) ([]*model.GroupPost
 	var posts []*model.GroupPost
 	for rows.Next() {
 		post := &model.GroupPost{}
-		err := rows.Scan(&post.ID, &post.GroupID, &post.UserID, &post.Content, &post.MediaURL, &post.CreatedAt)
+		err := rows.Scan(&post.ID, &post.GroupID, &post.UserID, &post.Content, &post.MediaURL,
+			&post.ContentWarning, &post.Sensitive, &post.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -138,6 +142,19 @@ func (r *GroupRepository) GetPosts(groupID int64, limit int) ([]*model.GroupPost
 	return posts, rows.Err()
 }
 
+func (r *GroupRepository) SetPostFlags(id int64, contentWarning string, sensitive bool) error {
+	query := `UPDATE group_posts SET content_warning = ?, sensitive = ? WHERE id = ?`
+	result, err := r.db.Exec(query, contentWarning, sensitive, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.New("group post not found")
+	}
+	return nil
+}
+
 func (r *GroupRepository) GetUserGroups(userID int64) ([]*model.Group, error) {
 	query := `SELECT g.id, g.owner_id, g.title, g.description, COALESCE(g.avatar_url, ''), g.created_at
 			  FROM groups g
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"database/sql"
+	"socialnet/internal/model"
+)
+
+type LinkPreviewRepository struct {
+	db *sql.DB
+}
+
+func NewLinkPreviewRepository(db *sql.DB) *LinkPreviewRepository {
+	return &LinkPreviewRepository{db: db}
+}
+
+// CreatePending records url for fetching and reports whether it was new.
+func (r *LinkPreviewRepository) CreatePending(url string) (bool, error) {
+	result, err := r.db.Exec(`INSERT OR IGNORE INTO link_previews (url, status) VALUES (?, ?)`, url, model.LinkPreviewPending)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func (r *LinkPreviewRepository) GetByURL(url string) (*model.LinkPreview, error) {
+	query := `SELECT url, status, COALESCE(title, ''), COALESCE(description, ''), COALESCE(image_url, ''),
+			  COALESCE(site_name, ''), fetched_at FROM link_previews WHERE url = ?`
+	preview := &model.LinkPreview{}
+	err := r.db.QueryRow(query, url).Scan(&preview.URL, &preview.Status, &preview.Title, &preview.Description,
+		&preview.ImageURL, &preview.SiteName, &preview.FetchedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return preview, err
+}
+
+func (r *LinkPreviewRepository) Save(preview *model.LinkPreview) error {
+	query := `UPDATE link_previews SET status = ?, title = ?, description = ?, image_url = ?, site_name = ?,
+			  fetched_at = CURRENT_TIMESTAMP WHERE url = ?`
+	_, err := r.db.Exec(query, preview.Status, preview.Title, preview.Description, preview.ImageURL,
+		preview.SiteName, preview.URL)
+	return err
+}
+
+func (r *LinkPreviewRepository) GetPending(limit int) ([]string, error) {
+	rows, err := r.db.Query(`SELECT url FROM link_previews WHERE status = ? ORDER BY id ASC LIMIT ?`, model.LinkPreviewPending, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var urls []string
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		urls = append(urls, url)
+	}
+	return urls, rows.Err()
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"socialnet/internal/model"
+)
+
+type MediaRepository struct {
+	db *sql.DB
+}
+
+func NewMediaRepository(db *sql.DB) *MediaRepository {
+	return &MediaRepository{db: db}
+}
+
+func (r *MediaRepository) Create(media *model.Media) (int64, error) {
+	query := `INSERT INTO media (user_id, url, content_type, size) VALUES (?, ?, ?, ?)`
+	result, err := r.db.Exec(query, media.UserID, media.URL, media.ContentType, media.Size)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+func (r *MediaRepository) GetByID(id int64) (*model.Media, error) {
+	query := `SELECT id, user_id, url, content_type, size, created_at FROM media WHERE id = ?`
+	media := &model.Media{}
+	err := r.db.QueryRow(query, id).Scan(&media.ID, &media.UserID, &media.URL, &media.ContentType,
+		&media.Size, &media.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("media not found")
+	}
+	return media, err
+}
+
+func (r *MediaRepository) GetByURL(url string) (*model.Media, error) {
+	query := `SELECT id, user_id, url, content_type, size, created_at FROM media WHERE url = ?`
+	media := &model.Media{}
+	err := r.db.QueryRow(query, url).Scan(&media.ID, &media.UserID, &media.URL, &media.ContentType,
+		&media.Size, &media.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("media not found")
+	}
+	return media, err
+}
+
+// SetAttachments replaces the attachments of a post or group post.
+func (r *MediaRepository) SetAttachments(targetType model.AttachmentTargetType, targetID int64, attachments []*model.Attachment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM attachments WHERE target_type = ? AND target_id = ?`, targetType, targetID); err != nil {
+		return err
+	}
+
+	for _, a := range attachments {
+		if _, err := tx.Exec(`INSERT INTO attachments (target_type, target_id, media_id, position, alt_text) VALUES (?, ?, ?, ?, ?)`,
+			targetType, targetID, a.MediaID, a.Position, a.AltText); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *MediaRepository) GetAttachments(targetType model.AttachmentTargetType, targetID int64) ([]*model.Attachment, error) {
+	query := `SELECT a.media_id, m.url, COALESCE(a.alt_text, ''), a.position
+			  FROM attachments a JOIN media m ON m.id = a.media_id
+			  WHERE a.target_type = ? AND a.target_id = ? ORDER BY a.position ASC`
+	rows, err := r.db.Query(query, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []*model.Attachment
+	for rows.Next() {
+		a := &model.Attachment{}
+		if err := rows.Scan(&a.MediaID, &a.URL, &a.AltText, &a.Position); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
+
+// IsReferenced reports whether an uploaded file is still used anywhere.
+func (r *MediaRepository) IsReferenced(url string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM attachments a JOIN media m ON m.id = a.media_id WHERE m.url = ?)
+			  OR EXISTS(SELECT 1 FROM posts WHERE media_url = ?)
+			  OR EXISTS(SELECT 1 FROM post_revisions WHERE media_url = ?)
+			  OR EXISTS(SELECT 1 FROM group_posts WHERE media_url = ?)
+			  OR EXISTS(SELECT 1 FROM stories WHERE media_url = ?)
+			  OR EXISTS(SELECT 1 FROM users WHERE avatar_url = ?)
+			  OR EXISTS(SELECT 1 FROM groups WHERE avatar_url = ?)`
+	var referenced bool
+	err := r.db.QueryRow(query, url, url, url, url, url, url, url).Scan(&referenced)
+	return referenced, err
+}
+
+func (r *MediaRepository) DeleteByURL(url string) error {
+	_, err := r.db.Exec(`DELETE FROM media WHERE url = ?`, url)
+	return err
+}
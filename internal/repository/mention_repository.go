@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"database/sql"
+	"socialnet/internal/model"
+)
+
+type MentionRepository struct {
+	db *sql.DB
+}
+
+func NewMentionRepository(db *sql.DB) *MentionRepository {
+	return &MentionRepository{db: db}
+}
+
+// Create stores a mention and reports whether it is new. Re-saving the same
+// target (e.g. after an edit) leaves existing mentions untouched.
+func (r *MentionRepository) Create(mention *model.Mention) (bool, error) {
+	query := `INSERT OR IGNORE INTO mentions (user_id, author_id, target_type, target_id) VALUES (?, ?, ?, ?)`
+	result, err := r.db.Exec(query, mention.UserID, mention.AuthorID, mention.TargetType, mention.TargetID)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+func (r *MentionRepository) GetByTarget(targetType model.MentionTargetType, targetID int64) ([]*model.Mention, error) {
+	query := `SELECT id, user_id, author_id, target_type, target_id, created_at
+			  FROM mentions WHERE target_type = ? AND target_id = ? ORDER BY id ASC`
+	rows, err := r.db.Query(query, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mentions []*model.Mention
+	for rows.Next() {
+		m := &model.Mention{}
+		if err := rows.Scan(&m.ID, &m.UserID, &m.AuthorID, &m.TargetType, &m.TargetID, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		mentions = append(mentions, m)
+	}
+	return mentions, rows.Err()
+}
+
+func (r *MentionRepository) DeleteByTarget(targetType model.MentionTargetType, targetID int64) error {
+	query := `DELETE FROM mentions WHERE target_type = ? AND target_id = ?`
+	_, err := r.db.Exec(query, targetType, targetID)
+	return err
+}
@@ -2,9 +2,21 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"socialnet/internal/model"
 )
 
+// unreadMessages matches the messages in cm's conversation that its member
+// has not read: text messages from others past the member's read cursor,
+// leaving out anyone the member blocked or was blocked by.
+const unreadMessages = `um.conversation_id = cm.conversation_id
+	AND um.id > COALESCE(cm.last_read_message_id, 0) AND um.user_id != cm.user_id
+	AND COALESCE(um.type, 'text') = 'text'
+	AND um.user_id NOT IN (
+		SELECT CASE WHEN requester_id = cm.user_id THEN addressee_id ELSE requester_id END
+		FROM friendships WHERE (requester_id = cm.user_id OR addressee_id = cm.user_id) AND status = 'blocked'
+	)`
+
 type MessageRepository struct {
 	db *sql.DB
 }
@@ -13,26 +25,71 @@ func NewMessageRepository(db *sql.DB) *MessageRepository {
 	return &MessageRepository{db: db}
 }
 
-func (r *MessageRepository) CreateConversation() (int64, error) {
-	query := `INSERT INTO conversations DEFAULT VALUES`
-	result, err := r.db.Exec(query)
+func (r *MessageRepository) CreateConversation(title string, isGroup bool, createdBy int64) (int64, error) {
+	query := `INSERT INTO conversations (title, is_group, created_by) VALUES (?, ?, ?)`
+	result, err := r.db.Exec(query, title, isGroup, createdBy)
 	if err != nil {
 		return 0, err
 	}
 	return result.LastInsertId()
 }
 
-func (r *MessageRepository) AddMember(conversationID, userID int64) error {
-	query := `INSERT INTO conversation_members (conversation_id, user_id) VALUES (?, ?)`
-	_, err := r.db.Exec(query, conversationID, userID)
+func (r *MessageRepository) GetConversation(id int64) (*model.Conversation, error) {
+	query := `SELECT id, COALESCE(title, ''), COALESCE(is_group, FALSE), created_at FROM conversations WHERE id = ?`
+	conversation := &model.Conversation{}
+	err := r.db.QueryRow(query, id).Scan(&conversation.ID, &conversation.Title, &conversation.IsGroup,
+		&conversation.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("conversation not found")
+	}
+	return conversation, err
+}
+
+func (r *MessageRepository) UpdateTitle(conversationID int64, title string) error {
+	_, err := r.db.Exec(`UPDATE conversations SET title = ? WHERE id = ?`, title, conversationID)
 	return err
 }
 
+func (r *MessageRepository) AddMember(conversationID, userID, addedBy int64, role model.ConversationRole,
+	status model.ConversationMemberStatus) error {
+	// New members start with the existing history read.
+	query := `INSERT INTO conversation_members (conversation_id, user_id, added_by, role, status, last_read_message_id)
+			  VALUES (?, ?, ?, ?, ?, (SELECT COALESCE(MAX(id), 0) FROM messages WHERE conversation_id = ?))`
+	_, err := r.db.Exec(query, conversationID, userID, addedBy, role, status, conversationID)
+	return err
+}
+
+func (r *MessageRepository) RemoveMember(conversationID, userID int64) error {
+	_, err := r.db.Exec(`DELETE FROM conversation_members WHERE conversation_id = ? AND user_id = ?`,
+		conversationID, userID)
+	return err
+}
+
+func (r *MessageRepository) SetMemberMuted(conversationID, userID int64, muted bool) error {
+	query := `UPDATE conversation_members SET muted = ? WHERE conversation_id = ? AND user_id = ?`
+	_, err := r.db.Exec(query, muted, conversationID, userID)
+	return err
+}
+
+func (r *MessageRepository) SetMemberRole(conversationID, userID int64, role model.ConversationRole) error {
+	query := `UPDATE conversation_members SET role = ? WHERE conversation_id = ? AND user_id = ?`
+	_, err := r.db.Exec(query, role, conversationID, userID)
+	return err
+}
+
+func (r *MessageRepository) SetMemberStatus(conversationID, userID int64, status model.ConversationMemberStatus) error {
+	query := `UPDATE conversation_members SET status = ? WHERE conversation_id = ? AND user_id = ?`
+	_, err := r.db.Exec(query, status, conversationID, userID)
+	return err
+}
+
+// GetConversationBetween finds the direct conversation between two users.
+// Group conversations they share do not count.
 func (r *MessageRepository) GetConversationBetween(user1ID, user2ID int64) (*model.Conversation, error) {
 	query := `SELECT c.id, c.created_at FROM conversations c
 			  INNER JOIN conversation_members cm1 ON cm1.conversation_id = c.id
 			  INNER JOIN conversation_members cm2 ON cm2.conversation_id = c.id
-			  WHERE cm1.user_id = ? AND cm2.user_id = ?
+			  WHERE cm1.user_id = ? AND cm2.user_id = ? AND COALESCE(c.is_group, FALSE) = FALSE
 			  LIMIT 1`
 	conversation := &model.Conversation{}
 	err := r.db.QueryRow(query, user1ID, user2ID).Scan(&conversation.ID, &conversation.CreatedAt)
@@ -43,16 +100,81 @@ func (r *MessageRepository) GetConversationBetween(user1ID, user2ID int64) (*mod
 }
 
 func (r *MessageRepository) CreateMessage(message *model.Message) (int64, error) {
-	query := `INSERT INTO messages (conversation_id, user_id, body) VALUES (?, ?, ?)`
-	result, err := r.db.Exec(query, message.ConversationID, message.UserID, message.Body)
+	query := `INSERT INTO messages (conversation_id, user_id, type, body, story_id) VALUES (?, ?, ?, ?, ?)`
+	result, err := r.db.Exec(query, message.ConversationID, message.UserID, message.Type, message.Body,
+		message.StoryID)
 	if err != nil {
 		return 0, err
 	}
 	return result.LastInsertId()
 }
 
+func (r *MessageRepository) GetMessageByID(id int64) (*model.Message, error) {
+	query := `SELECT id, conversation_id, user_id, COALESCE(type, 'text'), body, story_id, created_at, read_at
+			  FROM messages WHERE id = ?`
+	message := &model.Message{}
+	err := r.db.QueryRow(query, id).Scan(&message.ID, &message.ConversationID, &message.UserID, &message.Type,
+		&message.Body, &message.StoryID, &message.CreatedAt, &message.ReadAt)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("message not found")
+	}
+	return message, err
+}
+
+// SetReadCursor moves userID's read cursor forward to messageID. It reports
+// whether the cursor moved.
+func (r *MessageRepository) SetReadCursor(conversationID, userID, messageID int64) (bool, error) {
+	result, err := r.db.Exec(`UPDATE conversation_members SET last_read_message_id = ?
+		WHERE conversation_id = ? AND user_id = ? AND COALESCE(last_read_message_id, 0) < ?`,
+		messageID, conversationID, userID, messageID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *MessageRepository) GetLatestMessageID(conversationID int64) (int64, error) {
+	var id int64
+	err := r.db.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM messages WHERE conversation_id = ?`,
+		conversationID).Scan(&id)
+	return id, err
+}
+
+func (r *MessageRepository) GetUnreadCount(conversationID, userID int64) (int, error) {
+	query := `SELECT COUNT(*) FROM conversation_members cm
+			  INNER JOIN messages um ON ` + unreadMessages + `
+			  WHERE cm.conversation_id = ? AND cm.user_id = ?`
+	var count int
+	err := r.db.QueryRow(query, conversationID, userID).Scan(&count)
+	return count, err
+}
+
+// GetUnreadSummary counts the unread messages in userID's accepted
+// conversations and how many conversations they are spread over.
+func (r *MessageRepository) GetUnreadSummary(userID int64) (*model.UnreadSummary, error) {
+	query := `SELECT COUNT(*), COUNT(DISTINCT um.conversation_id) FROM conversation_members cm
+			  INNER JOIN messages um ON ` + unreadMessages + `
+			  WHERE cm.user_id = ? AND COALESCE(cm.status, 'accepted') = 'accepted'`
+	summary := &model.UnreadSummary{}
+	err := r.db.QueryRow(query, userID).Scan(&summary.Messages, &summary.Conversations)
+	return summary, err
+}
+
+// MarkRead sets read_at on the messages up to upToID that other members sent
+// and userID has not read yet. It returns how many were marked.
+func (r *MessageRepository) MarkRead(conversationID, userID, upToID int64) (int64, error) {
+	result, err := r.db.Exec(`UPDATE messages SET read_at = CURRENT_TIMESTAMP
+		WHERE conversation_id = ? AND user_id != ? AND id <= ? AND read_at IS NULL`,
+		conversationID, userID, upToID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 func (r *MessageRepository) GetMessages(conversationID int64, limit int) ([]*model.Message, error) {
-	query := `SELECT id, conversation_id, user_id, body, created_at, read_at 
+	query := `SELECT id, conversation_id, user_id, COALESCE(type, 'text'), body, story_id, created_at, read_at
 			  FROM messages WHERE conversation_id = ? ORDER BY created_at ASC, id ASC LIMIT ?`
 	rows, err := r.db.Query(query, conversationID, limit)
 	if err != nil {
@@ -63,8 +185,8 @@ func (r *MessageRepository) GetMessages(conversationID int64, limit int) ([]*mod
 	var messages []*model.Message
 	for rows.Next() {
 		message := &model.Message{}
-		err := rows.Scan(&message.ID, &message.ConversationID, &message.UserID,
-			&message.Body, &message.CreatedAt, &message.ReadAt)
+		err := rows.Scan(&message.ID, &message.ConversationID, &message.UserID, &message.Type,
+			&message.Body, &message.StoryID, &message.CreatedAt, &message.ReadAt)
 		if err != nil {
 			return nil, err
 		}
@@ -73,19 +195,25 @@ func (r *MessageRepository) GetMessages(conversationID int64, limit int) ([]*mod
 	return messages, rows.Err()
 }
 
-func (r *MessageRepository) GetUserConversations(userID int64) ([]*model.Conversation, error) {
-	query := `SELECT c.id, c.created_at,
+// GetUserConversations lists the conversations where userID's membership has
+// the given status, most recently active first. Direct conversations carry
+// the other participant; group members are loaded separately.
+func (r *MessageRepository) GetUserConversations(userID int64, status model.ConversationMemberStatus) ([]*model.Conversation, error) {
+	query := `SELECT c.id, COALESCE(c.title, ''), COALESCE(c.is_group, FALSE), COALESCE(cm.muted, FALSE), c.created_at,
 			  u.id, u.email, u.username, u.full_name, u.bio, u.avatar_url, u.is_admin, u.created_at,
-			  m.id, m.conversation_id, m.user_id, m.body, m.created_at, m.read_at
+			  m.id, m.conversation_id, m.user_id, m.type, m.body, m.created_at, m.read_at,
+			  (SELECT COUNT(*) FROM messages um WHERE ` + unreadMessages + `)
 			  FROM conversations c
 			  INNER JOIN conversation_members cm ON cm.conversation_id = c.id AND cm.user_id = ?
+				AND COALESCE(cm.status, 'accepted') = ?
 			  LEFT JOIN conversation_members cm_other ON cm_other.conversation_id = c.id AND cm_other.user_id != ?
+				AND COALESCE(c.is_group, FALSE) = FALSE
 			  LEFT JOIN users u ON u.id = cm_other.user_id
 			  LEFT JOIN messages m ON m.id = (
 				SELECT id FROM messages WHERE conversation_id = c.id ORDER BY created_at DESC, id DESC LIMIT 1
 			  )
 			  ORDER BY COALESCE(m.created_at, c.created_at) DESC`
-	rows, err := r.db.Query(query, userID, userID)
+	rows, err := r.db.Query(query, userID, status, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -108,15 +236,17 @@ func (r *MessageRepository) GetUserConversations(userID int64) ([]*model.Convers
 		var messageID sql.NullInt64
 		var messageConversationID sql.NullInt64
 		var messageUserID sql.NullInt64
+		var messageType sql.NullString
 		var messageBody sql.NullString
 		var messageCreatedAt sql.NullTime
 		var messageReadAt sql.NullTime
 
 		err := rows.Scan(
-			&conversation.ID, &conversation.CreatedAt,
+			&conversation.ID, &conversation.Title, &conversation.IsGroup, &conversation.Muted, &conversation.CreatedAt,
 			&participantID, &participantEmail, &participantUsername, &participantFullName,
 			&participantBio, &participantAvatarURL, &participantIsAdmin, &participantCreatedAt,
-			&messageID, &messageConversationID, &messageUserID, &messageBody, &messageCreatedAt, &messageReadAt,
+			&messageID, &messageConversationID, &messageUserID, &messageType, &messageBody, &messageCreatedAt, &messageReadAt,
+			&conversation.UnreadCount,
 		)
 		if err != nil {
 			return nil, err
@@ -140,6 +270,10 @@ func (r *MessageRepository) GetUserConversations(userID int64) ([]*model.Convers
 			lastMessage.ID = messageID.Int64
 			lastMessage.ConversationID = messageConversationID.Int64
 			lastMessage.UserID = messageUserID.Int64
+			lastMessage.Type = model.MessageText
+			if messageType.Valid {
+				lastMessage.Type = model.MessageType(messageType.String)
+			}
 			lastMessage.Body = messageBody.String
 			if messageCreatedAt.Valid {
 				lastMessage.CreatedAt = messageCreatedAt.Time
@@ -162,3 +296,24 @@ func (r *MessageRepository) IsMember(conversationID, userID int64) (bool, error)
 	err := r.db.QueryRow(query, conversationID, userID).Scan(&exists)
 	return exists, err
 }
+
+// GetMembers returns each member of the conversation keyed by user ID.
+func (r *MessageRepository) GetMembers(conversationID int64) (map[int64]*model.ConversationMember, error) {
+	rows, err := r.db.Query(`SELECT user_id, COALESCE(added_by, 0), COALESCE(role, 'member'),
+		COALESCE(status, 'accepted'), COALESCE(last_read_message_id, 0), COALESCE(muted, FALSE), joined_at FROM conversation_members WHERE conversation_id = ?`, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := make(map[int64]*model.ConversationMember)
+	for rows.Next() {
+		member := &model.ConversationMember{}
+		if err := rows.Scan(&member.UserID, &member.AddedBy, &member.Role, &member.Status, &member.LastReadMessageID,
+			&member.Muted, &member.JoinedAt); err != nil {
+			return nil, err
+		}
+		members[member.UserID] = member
+	}
+	return members, rows.Err()
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"socialnet/internal/model"
+)
+
+type MutedWordRepository struct {
+	db *sql.DB
+}
+
+func NewMutedWordRepository(db *sql.DB) *MutedWordRepository {
+	return &MutedWordRepository{db: db}
+}
+
+// Create adds a rule, replacing the options of an existing rule for the same
+// phrase.
+func (r *MutedWordRepository) Create(word *model.MutedWord) (int64, error) {
+	query := `INSERT INTO muted_words (user_id, phrase, whole_word, expires_at) VALUES (?, ?, ?, ?)
+			  ON CONFLICT(user_id, phrase) DO UPDATE SET whole_word = excluded.whole_word,
+			  expires_at = excluded.expires_at, created_at = CURRENT_TIMESTAMP`
+	if _, err := r.db.Exec(query, word.UserID, word.Phrase, word.WholeWord, formatTimestamp(word.ExpiresAt)); err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err := r.db.QueryRow(`SELECT id FROM muted_words WHERE user_id = ? AND phrase = ?`,
+		word.UserID, word.Phrase).Scan(&id)
+	return id, err
+}
+
+func (r *MutedWordRepository) GetByID(id int64) (*model.MutedWord, error) {
+	query := `SELECT id, user_id, phrase, whole_word, expires_at, created_at FROM muted_words WHERE id = ?`
+	word := &model.MutedWord{}
+	err := r.db.QueryRow(query, id).Scan(&word.ID, &word.UserID, &word.Phrase, &word.WholeWord,
+		&word.ExpiresAt, &word.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("muted word not found")
+	}
+	return word, err
+}
+
+// GetActive lists the user's rules that have not expired, newest first.
+func (r *MutedWordRepository) GetActive(userID int64) ([]*model.MutedWord, error) {
+	query := `SELECT id, user_id, phrase, whole_word, expires_at, created_at FROM muted_words
+			  WHERE user_id = ? AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+			  ORDER BY created_at DESC, id DESC`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var words []*model.MutedWord
+	for rows.Next() {
+		word := &model.MutedWord{}
+		if err := rows.Scan(&word.ID, &word.UserID, &word.Phrase, &word.WholeWord,
+			&word.ExpiresAt, &word.CreatedAt); err != nil {
+			return nil, err
+		}
+		words = append(words, word)
+	}
+	return words, rows.Err()
+}
+
+func (r *MutedWordRepository) CountActive(userID int64) (int, error) {
+	query := `SELECT COUNT(*) FROM muted_words
+			  WHERE user_id = ? AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)`
+	var count int
+	err := r.db.QueryRow(query, userID).Scan(&count)
+	return count, err
+}
+
+func (r *MutedWordRepository) Delete(id int64) error {
+	_, err := r.db.Exec(`DELETE FROM muted_words WHERE id = ?`, id)
+	return err
+}
+
+func (r *MutedWordRepository) DeleteExpired() error {
+	_, err := r.db.Exec(`DELETE FROM muted_words WHERE expires_at <= CURRENT_TIMESTAMP`)
+	return err
+}
@@ -15,19 +15,23 @@ func NewNotificationRepository(db *sql.DB) *NotificationRepository {
 }
 
 func (r *NotificationRepository) Create(notification *model.Notification) (int64, error) {
-	query := `INSERT INTO notifications (user_id, type, target_id, message) VALUES (?, ?, ?, ?)`
+	query := `INSERT INTO notifications (user_id, type, target_id, message, muted, actor_id, count)
+			  VALUES (?, ?, ?, ?, ?, ?, 1)`
 	result, err := r.db.Exec(query, notification.UserID, notification.Type,
-		notification.TargetID, notification.Message)
+		notification.TargetID, notification.Message, notification.Muted, notification.ActorID)
 	if err != nil {
 		return 0, err
 	}
 	return result.LastInsertId()
 }
 
-func (r *NotificationRepository) GetByUser(userID int64, limit int) ([]*model.Notification, error) {
-	query := `SELECT id, user_id, type, target_id, message, read, created_at 
-			  FROM notifications WHERE user_id = ? ORDER BY created_at DESC LIMIT ?`
-	rows, err := r.db.Query(query, userID, limit)
+// GetByUser lists the user's notifications, leaving out muted ones unless
+// includeMuted is set.
+func (r *NotificationRepository) GetByUser(userID int64, limit int, includeMuted bool) ([]*model.Notification, error) {
+	query := `SELECT id, user_id, type, target_id, message, read, muted, COALESCE(actor_id, 0),
+			  COALESCE(count, 1), created_at
+			  FROM notifications WHERE user_id = ? AND (? OR muted = FALSE) ORDER BY created_at DESC LIMIT ?`
+	rows, err := r.db.Query(query, userID, includeMuted, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +42,8 @@ func (r *NotificationRepository) GetByUser(userID int64, limit int) ([]*model.No
 		notification := &model.Notification{}
 		var targetID sql.NullInt64
 		err := rows.Scan(&notification.ID, &notification.UserID, &notification.Type,
-			&targetID, &notification.Message, &notification.Read, &notification.CreatedAt)
+			&targetID, &notification.Message, &notification.Read, &notification.Muted, &notification.ActorID,
+			&notification.Count, &notification.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -50,6 +55,30 @@ func (r *NotificationRepository) GetByUser(userID int64, limit int) ([]*model.No
 	return notifications, rows.Err()
 }
 
+// FindUnread returns the newest unread notification like notification, from
+// the same actor about the same target with the same muted state, created or
+// last bumped after since. It returns nil if there is none.
+func (r *NotificationRepository) FindUnread(notification *model.Notification, since time.Time) (*model.Notification, error) {
+	query := `SELECT id, COALESCE(count, 1) FROM notifications
+			  WHERE user_id = ? AND type = ? AND target_id = ? AND actor_id = ? AND muted = ?
+			  AND read = FALSE AND created_at > ?
+			  ORDER BY id DESC LIMIT 1`
+	existing := &model.Notification{}
+	err := r.db.QueryRow(query, notification.UserID, notification.Type, notification.TargetID,
+		notification.ActorID, notification.Muted, formatTimestamp(&since)).Scan(&existing.ID, &existing.Count)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return existing, err
+}
+
+// Bump folds another event into a notification, moving it back to the top.
+func (r *NotificationRepository) Bump(id int64, count int, message string) error {
+	query := `UPDATE notifications SET count = ?, message = ?, created_at = CURRENT_TIMESTAMP WHERE id = ?`
+	_, err := r.db.Exec(query, count, message, id)
+	return err
+}
+
 func (r *NotificationRepository) MarkAsRead(id int64) error {
 	query := `UPDATE notifications SET read = TRUE WHERE id = ?`
 	_, err := r.db.Exec(query, id)
@@ -70,7 +99,7 @@ func (r *NotificationRepository) DeleteOld(olderThan time.Duration) error {
 }
 
 func (r *NotificationRepository) GetUnreadCount(userID int64) (int, error) {
-	query := `SELECT COUNT(*) FROM notifications WHERE user_id = ? AND read = FALSE`
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = ? AND read = FALSE AND muted = FALSE`
 	var count int
 	err := r.db.QueryRow(query, userID).Scan(&count)
 	return count, err
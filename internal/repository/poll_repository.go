@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"socialnet/internal/model"
+	"time"
+)
+
+type PollRepository struct {
+	db *sql.DB
+}
+
+func NewPollRepository(db *sql.DB) *PollRepository {
+	return &PollRepository{db: db}
+}
+
+// Create stores a poll with its options, filling in their IDs.
+func (r *PollRepository) Create(poll *model.Poll) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO polls (post_id, multiple, anonymous, closes_at) VALUES (?, ?, ?, ?)`
+	result, err := tx.Exec(query, poll.PostID, poll.Multiple, poll.Anonymous, formatTimestamp(poll.ClosesAt))
+	if err != nil {
+		return 0, err
+	}
+	pollID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, option := range poll.Options {
+		result, err := tx.Exec(`INSERT INTO poll_options (poll_id, position, text) VALUES (?, ?, ?)`,
+			pollID, option.Position, option.Text)
+		if err != nil {
+			return 0, err
+		}
+		if option.ID, err = result.LastInsertId(); err != nil {
+			return 0, err
+		}
+	}
+
+	poll.ID = pollID
+	return pollID, tx.Commit()
+}
+
+// GetByPostID returns the poll attached to a post with its options, or nil if
+// the post has none.
+func (r *PollRepository) GetByPostID(postID int64) (*model.Poll, error) {
+	query := `SELECT id, post_id, multiple, anonymous, closes_at FROM polls WHERE post_id = ?`
+	poll := &model.Poll{}
+	err := r.db.QueryRow(query, postID).Scan(&poll.ID, &poll.PostID, &poll.Multiple, &poll.Anonymous, &poll.ClosesAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`SELECT id, position, text FROM poll_options WHERE poll_id = ? ORDER BY position ASC`, poll.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		option := &model.PollOption{}
+		if err := rows.Scan(&option.ID, &option.Position, &option.Text); err != nil {
+			return nil, err
+		}
+		poll.Options = append(poll.Options, option)
+	}
+	return poll, rows.Err()
+}
+
+// Vote records a user's choices. Each user votes once per poll.
+func (r *PollRepository) Vote(pollID, userID int64, optionIDs []int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var voted bool
+	if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM poll_votes WHERE poll_id = ? AND user_id = ?)`,
+		pollID, userID).Scan(&voted); err != nil {
+		return err
+	}
+	if voted {
+		return errors.New("already voted")
+	}
+
+	for _, optionID := range optionIDs {
+		if _, err := tx.Exec(`INSERT INTO poll_votes (poll_id, option_id, user_id) VALUES (?, ?, ?)`,
+			pollID, optionID, userID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *PollRepository) GetUserVotes(pollID, userID int64) ([]int64, error) {
+	rows, err := r.db.Query(`SELECT option_id FROM poll_votes WHERE poll_id = ? AND user_id = ? ORDER BY option_id`,
+		pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var optionIDs []int64
+	for rows.Next() {
+		var optionID int64
+		if err := rows.Scan(&optionID); err != nil {
+			return nil, err
+		}
+		optionIDs = append(optionIDs, optionID)
+	}
+	return optionIDs, rows.Err()
+}
+
+// GetVoteCounts returns the votes per option and the number of distinct voters.
+func (r *PollRepository) GetVoteCounts(pollID int64) (map[int64]int, int, error) {
+	rows, err := r.db.Query(`SELECT option_id, COUNT(*) FROM poll_votes WHERE poll_id = ? GROUP BY option_id`, pollID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var optionID int64
+		var count int
+		if err := rows.Scan(&optionID, &count); err != nil {
+			return nil, 0, err
+		}
+		counts[optionID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var voters int
+	err = r.db.QueryRow(`SELECT COUNT(DISTINCT user_id) FROM poll_votes WHERE poll_id = ?`, pollID).Scan(&voters)
+	return counts, voters, err
+}
+
+func (r *PollRepository) GetVoterIDs(optionID int64) ([]int64, error) {
+	rows, err := r.db.Query(`SELECT user_id FROM poll_votes WHERE option_id = ? ORDER BY created_at ASC, id ASC`, optionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
+}
+
+// GetClosedUnnotified returns polls on published posts that have closed but
+// whose authors have not been told yet.
+func (r *PollRepository) GetClosedUnnotified(now time.Time, limit int) ([]*model.Poll, error) {
+	query := `SELECT pl.id, pl.post_id, pl.multiple, pl.anonymous, pl.closes_at
+			  FROM polls pl JOIN posts p ON p.id = pl.post_id
+			  WHERE pl.close_notified = FALSE AND pl.closes_at <= ? AND p.status = 'published'
+			  ORDER BY pl.closes_at ASC LIMIT ?`
+	rows, err := r.db.Query(query, formatTimestamp(&now), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var polls []*model.Poll
+	for rows.Next() {
+		poll := &model.Poll{}
+		if err := rows.Scan(&poll.ID, &poll.PostID, &poll.Multiple, &poll.Anonymous, &poll.ClosesAt); err != nil {
+			return nil, err
+		}
+		polls = append(polls, poll)
+	}
+	return polls, rows.Err()
+}
+
+// MarkCloseNotified claims a closed poll for notification. It reports false
+// if another run already did.
+func (r *PollRepository) MarkCloseNotified(pollID int64) (bool, error) {
+	result, err := r.db.Exec(`UPDATE polls SET close_notified = TRUE WHERE id = ? AND close_notified = FALSE`, pollID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
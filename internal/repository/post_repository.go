@@ -4,8 +4,43 @@ import (
 	"database/sql"
 	"errors"
 	"socialnet/internal/model"
+	"time"
 )
 
+// postAudienceCondition is true when the viewer is the author of the post
+// aliased as p, one of their friends or an approved follower. Bind the viewer
+// ID four times.
+const postAudienceCondition = `p.user_id = ? OR EXISTS(
+	SELECT 1 FROM friendships vf WHERE vf.status = 'accepted'
+	AND ((vf.requester_id = ? AND vf.addressee_id = p.user_id) OR (vf.addressee_id = ? AND vf.requester_id = p.user_id))
+) OR EXISTS(
+	SELECT 1 FROM follows vfl WHERE vfl.follower_id = ? AND vfl.followee_id = p.user_id AND vfl.status = 'accepted'
+)`
+
+// visiblePostCondition limits the posts aliased as p to the published ones the
+// viewer may see: those of public accounts, and otherwise only when the viewer
+// is in the post's audience. Posts of users blocked either way are excluded.
+// Bind the viewer ID six times.
+const visiblePostCondition = `(p.status = 'published' AND (` + postAudienceCondition + ` OR EXISTS(
+	SELECT 1 FROM users vu WHERE vu.id = p.user_id AND vu.private_account = FALSE
+)) AND NOT EXISTS(
+	SELECT 1 FROM friendships vb WHERE vb.status = 'blocked'
+	AND ((vb.requester_id = ? AND vb.addressee_id = p.user_id) OR (vb.addressee_id = ? AND vb.requester_id = p.user_id))
+))`
+
+// feedPostCondition limits the posts aliased as p to the viewer's own,
+// their friends' and those of accounts they follow. Bind the viewer ID four
+// times.
+const feedPostCondition = `(p.status = 'published' AND (` + postAudienceCondition + `))`
+
+const postColumns = `p.id, p.user_id, p.content, COALESCE(p.media_url, ''), p.repost_of_id, p.quote_of_id,
+			  p.status, p.publish_at, p.created_at, p.updated_at, p.edited_at,
+			  COALESCE(p.content_warning, ''), COALESCE(p.sensitive, FALSE), COALESCE(p.flags_locked, FALSE)`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
 type PostRepository struct {
 	db *sql.DB
 }
@@ -14,30 +49,81 @@ func NewPostRepository(db *sql.DB) *PostRepository {
 	return &PostRepository{db: db}
 }
 
+// Create stores a post and, unless it is a plain repost, its first revision.
 func (r *PostRepository) Create(post *model.Post) (int64, error) {
-	query := `INSERT INTO posts (user_id, content, media_url) VALUES (?, ?, ?)`
-	result, err := r.db.Exec(query, post.UserID, post.Content, post.MediaURL)
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if post.Status == "" {
+		post.Status = model.PostStatusPublished
+	}
+
+	query := `INSERT INTO posts (user_id, content, media_url, repost_of_id, quote_of_id, status, publish_at,
+			  content_warning, sensitive)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := tx.Exec(query, post.UserID, post.Content, post.MediaURL, post.RepostOfID, post.QuoteOfID,
+		post.Status, formatTimestamp(post.PublishAt), post.ContentWarning, post.Sensitive)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
 	if err != nil {
 		return 0, err
 	}
-	return result.LastInsertId()
+
+	if post.RepostOfID == nil {
+		if err := addRevision(tx, id, post.Content, post.MediaURL); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, tx.Commit()
 }
 
 func (r *PostRepository) GetByID(id int64) (*model.Post, error) {
-	query := `SELECT id, user_id, content, media_url, created_at, updated_at FROM posts WHERE id = ?`
-	post := &model.Post{}
-	err := r.db.QueryRow(query, id).Scan(
-		&post.ID, &post.UserID, &post.Content, &post.MediaURL, &post.CreatedAt, &post.UpdatedAt,
-	)
+	query := `SELECT ` + postColumns + ` FROM posts p WHERE p.id = ?`
+	post, err := scanPost(r.db.QueryRow(query, id))
 	if err == sql.ErrNoRows {
 		return nil, errors.New("post not found")
 	}
 	return post, err
 }
 
+// Update overwrites the post and records the new content as its next revision.
 func (r *PostRepository) Update(post *model.Post) error {
-	query := `UPDATE posts SET content = ?, media_url = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
-	result, err := r.db.Exec(query, post.Content, post.MediaURL, post.ID)
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE posts SET content = ?, media_url = ?, content_warning = ?, sensitive = ?,
+			  updated_at = CURRENT_TIMESTAMP,
+			  edited_at = CASE WHEN status = 'published' THEN CURRENT_TIMESTAMP ELSE edited_at END WHERE id = ?`
+	result, err := tx.Exec(query, post.Content, post.MediaURL, post.ContentWarning, post.Sensitive, post.ID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.New("post not found")
+	}
+
+	if err := addRevision(tx, post.ID, post.Content, post.MediaURL); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetFlags applies moderator flags. Locked flags can no longer be changed by
+// the author.
+func (r *PostRepository) SetFlags(id int64, contentWarning string, sensitive, locked bool) error {
+	query := `UPDATE posts SET content_warning = ?, sensitive = ?, flags_locked = ? WHERE id = ?`
+	result, err := r.db.Exec(query, contentWarning, sensitive, locked, id)
 	if err != nil {
 		return err
 	}
@@ -48,7 +134,65 @@ func (r *PostRepository) Update(post *model.Post) error {
 	return nil
 }
 
+func addRevision(tx *sql.Tx, postID int64, content, mediaURL string) error {
+	query := `INSERT INTO post_revisions (post_id, revision, content, media_url)
+			  SELECT ?, COALESCE(MAX(revision), 0) + 1, ?, ? FROM post_revisions WHERE post_id = ?`
+	_, err := tx.Exec(query, postID, content, mediaURL, postID)
+	return err
+}
+
+// GetRevisions returns a post's revisions, oldest first.
+func (r *PostRepository) GetRevisions(postID int64) ([]*model.PostRevision, error) {
+	query := `SELECT id, post_id, revision, content, COALESCE(media_url, ''), created_at
+			  FROM post_revisions WHERE post_id = ? ORDER BY revision ASC`
+	rows, err := r.db.Query(query, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []*model.PostRevision
+	for rows.Next() {
+		revision := &model.PostRevision{}
+		err := rows.Scan(&revision.ID, &revision.PostID, &revision.Revision, &revision.Content,
+			&revision.MediaURL, &revision.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *PostRepository) GetLatestRevisionID(postID int64) (int64, error) {
+	query := `SELECT id FROM post_revisions WHERE post_id = ? ORDER BY revision DESC LIMIT 1`
+	var id int64
+	err := r.db.QueryRow(query, postID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, errors.New("revision not found")
+	}
+	return id, err
+}
+
+func (r *PostRepository) GetRevisionByID(id int64) (*model.PostRevision, error) {
+	query := `SELECT id, post_id, revision, content, COALESCE(media_url, ''), created_at
+			  FROM post_revisions WHERE id = ?`
+	revision := &model.PostRevision{}
+	err := r.db.QueryRow(query, id).Scan(&revision.ID, &revision.PostID, &revision.Revision, &revision.Content,
+		&revision.MediaURL, &revision.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("revision not found")
+	}
+	return revision, err
+}
+
+// Delete removes a post together with the plain reposts of it, which have no
+// content of their own. Quote posts are kept and show the original as gone.
 func (r *PostRepository) Delete(id int64) error {
+	if _, err := r.db.Exec(`DELETE FROM posts WHERE repost_of_id = ?`, id); err != nil {
+		return err
+	}
+
 	query := `DELETE FROM posts WHERE id = ?`
 	result, err := r.db.Exec(query, id)
 	if err != nil {
@@ -62,8 +206,8 @@ func (r *PostRepository) Delete(id int64) error {
 }
 
 func (r *PostRepository) GetUserPosts(userID int64, limit int) ([]*model.Post, error) {
-	query := `SELECT id, user_id, content, media_url, created_at, updated_at 
-			  FROM posts WHERE user_id = ? ORDER BY created_at DESC LIMIT ?`
+	query := `SELECT ` + postColumns + ` 
+			  FROM posts p WHERE p.user_id = ? AND p.status = 'published' ORDER BY p.created_at DESC LIMIT ?`
 	rows, err := r.db.Query(query, userID, limit)
 	if err != nil {
 		return nil, err
@@ -74,12 +218,10 @@ func (r *PostRepository) GetUserPosts(userID int64, limit int) ([]*model.Post, e
 }
 
 func (r *PostRepository) GetFeed(userID int64, limit int) ([]*model.Post, error) {
-	query := `SELECT DISTINCT p.id, p.user_id, p.content, p.media_url, p.created_at, p.updated_at
+	query := `SELECT ` + postColumns + `
 			  FROM posts p
-			  LEFT JOIN friendships f ON (f.requester_id = ? OR f.addressee_id = ?)
-			  WHERE (p.user_id = ? OR p.user_id = f.requester_id OR p.user_id = f.addressee_id)
-			  AND (f.status = 'accepted' OR p.user_id = ?)
-			  ORDER BY p.created_at DESC LIMIT ?`
+			  WHERE ` + feedPostCondition + `
+			  ORDER BY p.created_at DESC, p.id DESC LIMIT ?`
 	rows, err := r.db.Query(query, userID, userID, userID, userID, limit)
 	if err != nil {
 		return nil, err
@@ -89,12 +231,111 @@ func (r *PostRepository) GetFeed(userID int64, limit int) ([]*model.Post, error)
 	return r.scanPosts(rows)
 }
 
+func (r *PostRepository) IsVisibleTo(postID, viewerID int64) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM posts p WHERE p.id = ? AND ` + visiblePostCondition + `)`
+	var visible bool
+	err := r.db.QueryRow(query, postID, viewerID, viewerID, viewerID, viewerID, viewerID, viewerID).Scan(&visible)
+	return visible, err
+}
+
+func (r *PostRepository) GetRepostCounts(postID int64) (reposts int, quotes int, err error) {
+	query := `SELECT COALESCE(SUM(repost_of_id = ?), 0), COALESCE(SUM(quote_of_id = ?), 0)
+			  FROM posts WHERE (repost_of_id = ? OR quote_of_id = ?) AND status = 'published'`
+	err = r.db.QueryRow(query, postID, postID, postID, postID).Scan(&reposts, &quotes)
+	return reposts, quotes, err
+}
+
+func (r *PostRepository) HasUserReposted(postID, userID int64) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM posts WHERE repost_of_id = ? AND user_id = ?)`
+	var exists bool
+	err := r.db.QueryRow(query, postID, userID).Scan(&exists)
+	return exists, err
+}
+
+func (r *PostRepository) DeleteRepost(postID, userID int64) error {
+	query := `DELETE FROM posts WHERE repost_of_id = ? AND user_id = ?`
+	result, err := r.db.Exec(query, postID, userID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.New("repost not found")
+	}
+	return nil
+}
+
+// GetUnpublished returns the user's drafts and scheduled posts, the scheduled
+// ones first in publishing order.
+func (r *PostRepository) GetUnpublished(userID int64) ([]*model.Post, error) {
+	query := `SELECT ` + postColumns + `
+			  FROM posts p WHERE p.user_id = ? AND p.status IN ('draft', 'scheduled')
+			  ORDER BY p.publish_at IS NULL, p.publish_at ASC, p.updated_at DESC`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanPosts(rows)
+}
+
+// SetSchedule moves an unpublished post between draft and scheduled.
+func (r *PostRepository) SetSchedule(id int64, status model.PostStatus, publishAt *time.Time) error {
+	query := `UPDATE posts SET status = ?, publish_at = ?, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = ? AND status IN ('draft', 'scheduled')`
+	result, err := r.db.Exec(query, status, formatTimestamp(publishAt), id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.New("post is already published")
+	}
+	return nil
+}
+
+// Publish makes an unpublished post live, dated to now. It reports false if
+// the post was already published, so each post is only published once.
+func (r *PostRepository) Publish(id int64) (bool, error) {
+	query := `UPDATE posts SET status = 'published', publish_at = NULL,
+			  created_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = ? AND status IN ('draft', 'scheduled')`
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// GetDueScheduled returns scheduled posts whose publish time has passed.
+func (r *PostRepository) GetDueScheduled(now time.Time, limit int) ([]*model.Post, error) {
+	query := `SELECT ` + postColumns + `
+			  FROM posts p WHERE p.status = 'scheduled' AND p.publish_at <= ?
+			  ORDER BY p.publish_at ASC LIMIT ?`
+	rows, err := r.db.Query(query, formatTimestamp(&now), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanPosts(rows)
+}
+
+// formatTimestamp stores times in the same UTC layout as CURRENT_TIMESTAMP so
+// they compare correctly in SQL.
+func formatTimestamp(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.DateTime)
+}
+
 func (r *PostRepository) scanPosts(rows *sql.Rows) ([]*model.Post, error) {
 	var posts []*model.Post
 	for rows.Next() {
-		post := &model.Post{}
-		err := rows.Scan(&post.ID, &post.UserID, &post.Content, &post.MediaURL,
-			&post.CreatedAt, &post.UpdatedAt)
+		post, err := scanPost(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -102,3 +343,21 @@ func (r *PostRepository) scanPosts(rows *sql.Rows) ([]*model.Post, error) {
 	}
 	return posts, rows.Err()
 }
+
+func scanPost(row rowScanner) (*model.Post, error) {
+	post := &model.Post{}
+	var repostOfID, quoteOfID sql.NullInt64
+	err := row.Scan(&post.ID, &post.UserID, &post.Content, &post.MediaURL, &repostOfID, &quoteOfID,
+		&post.Status, &post.PublishAt, &post.CreatedAt, &post.UpdatedAt, &post.EditedAt,
+		&post.ContentWarning, &post.Sensitive, &post.FlagsLocked)
+	if err != nil {
+		return nil, err
+	}
+	if repostOfID.Valid {
+		post.RepostOfID = &repostOfID.Int64
+	}
+	if quoteOfID.Valid {
+		post.QuoteOfID = &quoteOfID.Int64
+	}
+	return post, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"database/sql"
+	"socialnet/internal/model"
+)
+
+type ReactionRepository struct {
+	db *sql.DB
+}
+
+func NewReactionRepository(db *sql.DB) *ReactionRepository {
+	return &ReactionRepository{db: db}
+}
+
+// Set records the user's reaction on a target, replacing any earlier one.
+func (r *ReactionRepository) Set(reaction *model.Reaction) error {
+	query := `INSERT INTO reactions (target_type, target_id, user_id, reaction) VALUES (?, ?, ?, ?)
+			  ON CONFLICT(target_type, target_id, user_id)
+			  DO UPDATE SET reaction = excluded.reaction, created_at = CURRENT_TIMESTAMP`
+	_, err := r.db.Exec(query, reaction.TargetType, reaction.TargetID, reaction.UserID, reaction.Reaction)
+	return err
+}
+
+func (r *ReactionRepository) Delete(targetType model.ReactionTargetType, targetID, userID int64) error {
+	query := `DELETE FROM reactions WHERE target_type = ? AND target_id = ? AND user_id = ?`
+	_, err := r.db.Exec(query, targetType, targetID, userID)
+	return err
+}
+
+// GetUserReaction returns the user's reaction on a target, or "" if none.
+func (r *ReactionRepository) GetUserReaction(targetType model.ReactionTargetType, targetID, userID int64) (string, error) {
+	query := `SELECT reaction FROM reactions WHERE target_type = ? AND target_id = ? AND user_id = ?`
+	var reaction string
+	err := r.db.QueryRow(query, targetType, targetID, userID).Scan(&reaction)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return reaction, err
+}
+
+func (r *ReactionRepository) GetCounts(targetType model.ReactionTargetType, targetID int64) (map[string]int, error) {
+	query := `SELECT reaction, COUNT(*) FROM reactions WHERE target_type = ? AND target_id = ? GROUP BY reaction`
+	rows, err := r.db.Query(query, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var reaction string
+		var count int
+		if err := rows.Scan(&reaction, &count); err != nil {
+			return nil, err
+		}
+		counts[reaction] = count
+	}
+	return counts, rows.Err()
+}
+
+// GetByTarget lists who reacted to a target, newest first. An empty reaction
+// returns every reaction.
+func (r *ReactionRepository) GetByTarget(targetType model.ReactionTargetType, targetID int64, reaction string, limit, offset int) ([]*model.Reaction, error) {
+	query := `SELECT id, target_type, target_id, user_id, reaction, created_at
+			  FROM reactions WHERE target_type = ? AND target_id = ? AND (? = '' OR reaction = ?)
+			  ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`
+	rows, err := r.db.Query(query, targetType, targetID, reaction, reaction, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reactions []*model.Reaction
+	for rows.Next() {
+		re := &model.Reaction{}
+		err := rows.Scan(&re.ID, &re.TargetType, &re.TargetID, &re.UserID, &re.Reaction, &re.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		reactions = append(reactions, re)
+	}
+	return reactions, rows.Err()
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"socialnet/internal/model"
 )
 
@@ -14,8 +15,8 @@ func NewReportRepository(db *sql.DB) *ReportRepository {
 }
 
 func (r *ReportRepository) Create(report *model.Report) (int64, error) {
-	query := `INSERT INTO reports (reporter_id, target_type, target_id, reason) VALUES (?, ?, ?, ?)`
-	result, err := r.db.Exec(query, report.ReporterID, report.TargetType, report.TargetID, report.Reason)
+	query := `INSERT INTO reports (reporter_id, target_type, target_id, reason, revision_id) VALUES (?, ?, ?, ?, ?)`
+	result, err := r.db.Exec(query, report.ReporterID, report.TargetType, report.TargetID, report.Reason, report.RevisionID)
 	if err != nil {
 		return 0, err
 	}
@@ -23,7 +24,7 @@ func (r *ReportRepository) Create(report *model.Report) (int64, error) {
 }
 
 func (r *ReportRepository) GetAll(status model.ReportStatus, limit int) ([]*model.Report, error) {
-	query := `SELECT id, reporter_id, target_type, target_id, reason, status, created_at 
+	query := `SELECT id, reporter_id, target_type, target_id, reason, status, revision_id, created_at 
 			  FROM reports WHERE status = ? ORDER BY created_at DESC LIMIT ?`
 	rows, err := r.db.Query(query, status, limit)
 	if err != nil {
@@ -35,12 +36,15 @@ func (r *ReportRepository) GetAll(status model.ReportStatus, limit int) ([]*mode
 }
 
 func (r *ReportRepository) GetByID(id int64) (*model.Report, error) {
-	query := `SELECT id, reporter_id, target_type, target_id, reason, status, created_at FROM reports WHERE id = ?`
+	query := `SELECT id, reporter_id, target_type, target_id, reason, status, revision_id, created_at FROM reports WHERE id = ?`
 	report := &model.Report{}
 	err := r.db.QueryRow(query, id).Scan(
 		&report.ID, &report.ReporterID, &report.TargetType, &report.TargetID,
-		&report.Reason, &report.Status, &report.CreatedAt,
+		&report.Reason, &report.Status, &report.RevisionID, &report.CreatedAt,
 	)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("report not found")
+	}
 	return report, err
 }
 
@@ -55,7 +59,7 @@ func (r *ReportRepository) scanReports(rows *sql.Rows) ([]*model.Report, error)
 	for rows.Next() {
 		report := &model.Report{}
 		err := rows.Scan(&report.ID, &report.ReporterID, &report.TargetType,
-			&report.TargetID, &report.Reason, &report.Status, &report.CreatedAt)
+			&report.TargetID, &report.Reason, &report.Status, &report.RevisionID, &report.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
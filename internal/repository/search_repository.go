@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"socialnet/internal/model"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Snippets wrap matches in these control characters so the service can escape
+// the surrounding text before turning them into markup.
+const (
+	SnippetMatchStart = "\x02"
+	SnippetMatchEnd   = "\x03"
+)
+
+const maxSearchWords = 10
+
+const snippetArgs = `char(2), char(3), '…', 16`
+
+// blockedPairCondition is true when the user in column col and the viewer
+// have blocked each other. Bind the viewer ID twice.
+const blockedPairCondition = `EXISTS(
+	SELECT 1 FROM friendships bf WHERE bf.status = 'blocked'
+	AND ((bf.requester_id = ? AND bf.addressee_id = %[1]s) OR (bf.addressee_id = ? AND bf.requester_id = %[1]s))
+)`
+
+type SearchRepository struct {
+	db *sql.DB
+}
+
+func NewSearchRepository(db *sql.DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+func (r *SearchRepository) SearchPosts(viewerID int64, filter *model.SearchFilter) ([]*model.SearchResult, error) {
+	query := `SELECT p.id, p.user_id, p.content, COALESCE(p.media_url, ''), p.created_at, p.updated_at,
+			  snippet(posts_fts, 0, ` + snippetArgs + `), bm25(posts_fts)
+			  FROM posts_fts
+			  INNER JOIN posts p ON p.id = posts_fts.rowid
+			  WHERE posts_fts MATCH ? AND ` + visiblePostCondition
+	args := []interface{}{FTSMatch(filter.Query), viewerID, viewerID, viewerID, viewerID, viewerID, viewerID}
+
+	cond, condArgs := filterConditions("p.user_id", "p.created_at", filter)
+	query += cond + ` ORDER BY bm25(posts_fts) LIMIT ?`
+	args = append(append(args, condArgs...), filter.Limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*model.SearchResult
+	for rows.Next() {
+		post := &model.Post{}
+		result := &model.SearchResult{Type: model.SearchTypePost, Post: post}
+		err := rows.Scan(&post.ID, &post.UserID, &post.Content, &post.MediaURL, &post.CreatedAt, &post.UpdatedAt,
+			&result.Snippet, &result.Rank)
+		if err != nil {
+			return nil, err
+		}
+		result.ID = post.ID
+		result.CreatedAt = post.CreatedAt
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+func (r *SearchRepository) SearchComments(viewerID int64, filter *model.SearchFilter) ([]*model.SearchResult, error) {
+	query := `SELECT c.id, c.post_id, c.user_id, c.content, c.created_at,
+			  snippet(comments_fts, 0, ` + snippetArgs + `), bm25(comments_fts)
+			  FROM comments_fts
+			  INNER JOIN comments c ON c.id = comments_fts.rowid
+			  INNER JOIN posts p ON p.id = c.post_id
+			  WHERE comments_fts MATCH ? AND ` + visiblePostCondition + `
+			  AND NOT ` + fmt.Sprintf(blockedPairCondition, "c.user_id")
+	args := []interface{}{FTSMatch(filter.Query), viewerID, viewerID, viewerID, viewerID, viewerID, viewerID,
+		viewerID, viewerID}
+
+	cond, condArgs := filterConditions("c.user_id", "c.created_at", filter)
+	query += cond + ` ORDER BY bm25(comments_fts) LIMIT ?`
+	args = append(append(args, condArgs...), filter.Limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*model.SearchResult
+	for rows.Next() {
+		comment := &model.Comment{}
+		result := &model.SearchResult{Type: model.SearchTypeComment, Comment: comment}
+		err := rows.Scan(&comment.ID, &comment.PostID, &comment.UserID, &comment.Content, &comment.CreatedAt,
+			&result.Snippet, &result.Rank)
+		if err != nil {
+			return nil, err
+		}
+		result.ID = comment.ID
+		result.CreatedAt = comment.CreatedAt
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+func (r *SearchRepository) SearchGroupPosts(viewerID int64, filter *model.SearchFilter) ([]*model.SearchResult, error) {
+	query := `SELECT gp.id, gp.group_id, gp.user_id, gp.content, COALESCE(gp.media_url, ''), gp.created_at,
+			  snippet(group_posts_fts, 0, ` + snippetArgs + `), bm25(group_posts_fts)
+			  FROM group_posts_fts
+			  INNER JOIN group_posts gp ON gp.id = group_posts_fts.rowid
+			  WHERE group_posts_fts MATCH ?
+			  AND EXISTS(SELECT 1 FROM group_members gm WHERE gm.group_id = gp.group_id AND gm.user_id = ?)
+			  AND NOT ` + fmt.Sprintf(blockedPairCondition, "gp.user_id")
+	args := []interface{}{FTSMatch(filter.Query), viewerID, viewerID, viewerID}
+
+	cond, condArgs := filterConditions("gp.user_id", "gp.created_at", filter)
+	query += cond + ` ORDER BY bm25(group_posts_fts) LIMIT ?`
+	args = append(append(args, condArgs...), filter.Limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*model.SearchResult
+	for rows.Next() {
+		post := &model.GroupPost{}
+		result := &model.SearchResult{Type: model.SearchTypeGroupPost, GroupPost: post}
+		err := rows.Scan(&post.ID, &post.GroupID, &post.UserID, &post.Content, &post.MediaURL, &post.CreatedAt,
+			&result.Snippet, &result.Rank)
+		if err != nil {
+			return nil, err
+		}
+		result.ID = post.ID
+		result.CreatedAt = post.CreatedAt
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+func (r *SearchRepository) SearchGroups(filter *model.SearchFilter) ([]*model.SearchResult, error) {
+	query := `SELECT g.id, g.owner_id, g.title, COALESCE(g.description, ''), COALESCE(g.avatar_url, ''), g.created_at,
+			  snippet(groups_fts, -1, ` + snippetArgs + `), bm25(groups_fts, 5.0, 1.0)
+			  FROM groups_fts
+			  INNER JOIN groups g ON g.id = groups_fts.rowid
+			  WHERE groups_fts MATCH ?`
+	args := []interface{}{FTSMatch(filter.Query)}
+
+	cond, condArgs := filterConditions("g.owner_id", "g.created_at", filter)
+	query += cond + ` ORDER BY bm25(groups_fts, 5.0, 1.0) LIMIT ?`
+	args = append(append(args, condArgs...), filter.Limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*model.SearchResult
+	for rows.Next() {
+		group := &model.Group{}
+		result := &model.SearchResult{Type: model.SearchTypeGroup, Group: group}
+		err := rows.Scan(&group.ID, &group.OwnerID, &group.Title, &group.Description, &group.AvatarURL, &group.CreatedAt,
+			&result.Snippet, &result.Rank)
+		if err != nil {
+			return nil, err
+		}
+		result.ID = group.ID
+		result.CreatedAt = group.CreatedAt
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+func (r *SearchRepository) SearchUsers(viewerID int64, filter *model.SearchFilter) ([]*model.SearchResult, error) {
+	query := `SELECT u.id, u.username, COALESCE(u.full_name, ''), COALESCE(u.bio, ''), COALESCE(u.avatar_url, ''),
+			  COALESCE(u.emoji_avatar, ''), COALESCE(u.is_online, 0), u.last_seen, COALESCE(u.show_last_seen, 'all'),
+			  COALESCE(u.private_account, TRUE), u.created_at, snippet(users_fts, -1, ` + snippetArgs + `), bm25(users_fts, 10.0, 5.0, 1.0)
+			  FROM users_fts
+			  INNER JOIN users u ON u.id = users_fts.rowid
+			  WHERE users_fts MATCH ? AND NOT ` + fmt.Sprintf(blockedPairCondition, "u.id")
+	args := []interface{}{FTSMatch(filter.Query), viewerID, viewerID}
+
+	cond, condArgs := filterConditions("", "u.created_at", filter)
+	query += cond + ` ORDER BY bm25(users_fts, 10.0, 5.0, 1.0) LIMIT ?`
+	args = append(append(args, condArgs...), filter.Limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*model.SearchResult
+	for rows.Next() {
+		user := &model.User{}
+		result := &model.SearchResult{Type: model.SearchTypeUser}
+		err := rows.Scan(&user.ID, &user.Username, &user.FullName, &user.Bio, &user.AvatarURL,
+			&user.EmojiAvatar, &user.IsOnline, &user.LastSeen, &user.ShowLastSeen, &user.PrivateAccount, &user.CreatedAt,
+			&result.Snippet, &result.Rank)
+		if err != nil {
+			return nil, err
+		}
+		result.ID = user.ID
+		result.CreatedAt = user.CreatedAt
+		result.User = user.ToPublic(viewerID, false)
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+// FTSMatch turns free text into an FTS5 query that requires every word, each
+// matched as a prefix. Quoting the words keeps FTS5 operators in user input
+// from being interpreted. It returns "" if the text has no searchable words.
+func FTSMatch(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > maxSearchWords {
+		words = words[:maxSearchWords]
+	}
+
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = `"` + word + `"*`
+	}
+	return strings.Join(terms, " ")
+}
+
+// filterConditions renders the author and date filters as extra WHERE clauses.
+// An empty authorColumn means the result type has no author.
+func filterConditions(authorColumn, createdColumn string, filter *model.SearchFilter) (string, []interface{}) {
+	var cond string
+	var args []interface{}
+
+	if filter.AuthorID != 0 && authorColumn != "" {
+		cond += ` AND ` + authorColumn + ` = ?`
+		args = append(args, filter.AuthorID)
+	}
+	if filter.From != nil {
+		cond += ` AND ` + createdColumn + ` >= ?`
+		args = append(args, filter.From.UTC().Format(time.DateTime))
+	}
+	if filter.To != nil {
+		cond += ` AND ` + createdColumn + ` < ?`
+		args = append(args, filter.To.UTC().Format(time.DateTime))
+	}
+
+	return cond, args
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"socialnet/internal/model"
+)
+
+const storyColumns = `s.id, s.user_id, COALESCE(s.content, ''), COALESCE(s.media_url, ''), s.created_at, s.expires_at`
+
+type StoryRepository struct {
+	db *sql.DB
+}
+
+func NewStoryRepository(db *sql.DB) *StoryRepository {
+	return &StoryRepository{db: db}
+}
+
+func (r *StoryRepository) Create(story *model.Story) (int64, error) {
+	query := `INSERT INTO stories (user_id, content, media_url, expires_at) VALUES (?, ?, ?, ?)`
+	result, err := r.db.Exec(query, story.UserID, story.Content, story.MediaURL, formatTimestamp(&story.ExpiresAt))
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// GetActiveByID returns a story that has not expired yet.
+func (r *StoryRepository) GetActiveByID(id int64) (*model.Story, error) {
+	query := `SELECT ` + storyColumns + ` FROM stories s WHERE s.id = ? AND s.expires_at > CURRENT_TIMESTAMP`
+	story := &model.Story{}
+	err := r.db.QueryRow(query, id).Scan(&story.ID, &story.UserID, &story.Content, &story.MediaURL,
+		&story.CreatedAt, &story.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("story not found")
+	}
+	return story, err
+}
+
+// GetTray returns the active stories of the viewer and their friends, grouped
+// by author and oldest first, with the viewer's seen flag.
+func (r *StoryRepository) GetTray(viewerID int64) ([]*model.Story, error) {
+	query := `SELECT ` + storyColumns + `,
+			  EXISTS(SELECT 1 FROM story_views v WHERE v.story_id = s.id AND v.viewer_id = ?)
+			  FROM stories s
+			  WHERE s.expires_at > CURRENT_TIMESTAMP AND (s.user_id = ? OR EXISTS(
+				SELECT 1 FROM friendships f WHERE f.status = 'accepted'
+				AND ((f.requester_id = ? AND f.addressee_id = s.user_id) OR (f.addressee_id = ? AND f.requester_id = s.user_id))
+			  ))
+			  ORDER BY s.user_id, s.created_at ASC, s.id ASC`
+	rows, err := r.db.Query(query, viewerID, viewerID, viewerID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stories []*model.Story
+	for rows.Next() {
+		story := &model.Story{}
+		if err := rows.Scan(&story.ID, &story.UserID, &story.Content, &story.MediaURL,
+			&story.CreatedAt, &story.ExpiresAt, &story.Viewed); err != nil {
+			return nil, err
+		}
+		stories = append(stories, story)
+	}
+	return stories, rows.Err()
+}
+
+func (r *StoryRepository) AddView(storyID, viewerID int64) error {
+	_, err := r.db.Exec(`INSERT OR IGNORE INTO story_views (story_id, viewer_id) VALUES (?, ?)`, storyID, viewerID)
+	return err
+}
+
+func (r *StoryRepository) GetViewCount(storyID int64) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM story_views WHERE story_id = ?`, storyID).Scan(&count)
+	return count, err
+}
+
+func (r *StoryRepository) GetViews(storyID int64) ([]*model.StoryView, error) {
+	query := `SELECT viewer_id, viewed_at FROM story_views WHERE story_id = ? ORDER BY viewed_at DESC`
+	rows, err := r.db.Query(query, storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var views []*model.StoryView
+	for rows.Next() {
+		view := &model.StoryView{}
+		if err := rows.Scan(&view.UserID, &view.ViewedAt); err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+	return views, rows.Err()
+}
+
+func (r *StoryRepository) Delete(id int64) error {
+	_, err := r.db.Exec(`DELETE FROM stories WHERE id = ?`, id)
+	return err
+}
+
+func (r *StoryRepository) GetExpired(limit int) ([]*model.Story, error) {
+	query := `SELECT ` + storyColumns + ` FROM stories s WHERE s.expires_at <= CURRENT_TIMESTAMP
+			  ORDER BY s.expires_at ASC LIMIT ?`
+	rows, err := r.db.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stories []*model.Story
+	for rows.Next() {
+		story := &model.Story{}
+		if err := rows.Scan(&story.ID, &story.UserID, &story.Content, &story.MediaURL,
+			&story.CreatedAt, &story.ExpiresAt); err != nil {
+			return nil, err
+		}
+		stories = append(stories, story)
+	}
+	return stories, rows.Err()
+}
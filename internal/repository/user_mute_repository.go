@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"database/sql"
+	"socialnet/internal/model"
+)
+
+type UserMuteRepository struct {
+	db *sql.DB
+}
+
+func NewUserMuteRepository(db *sql.DB) *UserMuteRepository {
+	return &UserMuteRepository{db: db}
+}
+
+// Create mutes a user, replacing the expiry of an existing mute.
+func (r *UserMuteRepository) Create(mute *model.UserMute) (int64, error) {
+	query := `INSERT INTO user_mutes (user_id, muted_user_id, expires_at) VALUES (?, ?, ?)
+			  ON CONFLICT(user_id, muted_user_id) DO UPDATE SET expires_at = excluded.expires_at,
+			  created_at = CURRENT_TIMESTAMP`
+	if _, err := r.db.Exec(query, mute.UserID, mute.MutedUserID, formatTimestamp(mute.ExpiresAt)); err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err := r.db.QueryRow(`SELECT id FROM user_mutes WHERE user_id = ? AND muted_user_id = ?`,
+		mute.UserID, mute.MutedUserID).Scan(&id)
+	return id, err
+}
+
+func (r *UserMuteRepository) GetByID(id int64) (*model.UserMute, error) {
+	query := `SELECT id, user_id, muted_user_id, expires_at, created_at FROM user_mutes WHERE id = ?`
+	mute := &model.UserMute{}
+	err := r.db.QueryRow(query, id).Scan(&mute.ID, &mute.UserID, &mute.MutedUserID, &mute.ExpiresAt, &mute.CreatedAt)
+	return mute, err
+}
+
+// GetActive lists the user's mutes that have not expired, newest first.
+func (r *UserMuteRepository) GetActive(userID int64) ([]*model.UserMute, error) {
+	query := `SELECT id, user_id, muted_user_id, expires_at, created_at FROM user_mutes
+			  WHERE user_id = ? AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+			  ORDER BY created_at DESC, id DESC`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mutes []*model.UserMute
+	for rows.Next() {
+		mute := &model.UserMute{}
+		if err := rows.Scan(&mute.ID, &mute.UserID, &mute.MutedUserID, &mute.ExpiresAt, &mute.CreatedAt); err != nil {
+			return nil, err
+		}
+		mutes = append(mutes, mute)
+	}
+	return mutes, rows.Err()
+}
+
+func (r *UserMuteRepository) IsMuted(userID, mutedUserID int64) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM user_mutes WHERE user_id = ? AND muted_user_id = ?
+			  AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP))`
+	var muted bool
+	err := r.db.QueryRow(query, userID, mutedUserID).Scan(&muted)
+	return muted, err
+}
+
+// Delete removes a mute and reports whether there was one.
+func (r *UserMuteRepository) Delete(userID, mutedUserID int64) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM user_mutes WHERE user_id = ? AND muted_user_id = ?`, userID, mutedUserID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+func (r *UserMuteRepository) DeleteExpired() error {
+	_, err := r.db.Exec(`DELETE FROM user_mutes WHERE expires_at <= CURRENT_TIMESTAMP`)
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"socialnet/internal/model"
+	"strings"
 	"time"
 )
 
@@ -30,14 +31,15 @@ func (r *UserRepository) Create(user *model.User) (int64, error) {
 func (r *UserRepository) GetByID(id int64) (*model.User, error) {
 	query := `SELECT id, email, username, password_hash, full_name, bio, avatar_url, 
 			  COALESCE(emoji_avatar, ''), is_admin, COALESCE(is_online, 0), last_seen,
-			  COALESCE(show_last_seen, 'all'), COALESCE(allow_messages_from, 'all'), firebase_uid, created_at 
+			  COALESCE(show_last_seen, 'all'), COALESCE(allow_messages_from, 'all'),
+			  COALESCE(private_account, TRUE), COALESCE(read_receipts, TRUE), firebase_uid, created_at 
 			  FROM users WHERE id = ?`
 	user := &model.User{}
 	err := r.db.QueryRow(query, id).Scan(
 		&user.ID, &user.Email, &user.Username, &user.PasswordHash,
 		&user.FullName, &user.Bio, &user.AvatarURL, &user.EmojiAvatar, &user.IsAdmin,
 		&user.IsOnline, &user.LastSeen, &user.ShowLastSeen, &user.AllowMessagesFrom,
-		&user.FirebaseUID, &user.CreatedAt,
+		&user.PrivateAccount, &user.ReadReceipts, &user.FirebaseUID, &user.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, errors.New("user not found")
@@ -48,14 +50,15 @@ func (r *UserRepository) GetByID(id int64) (*model.User, error) {
 func (r *UserRepository) GetByEmail(email string) (*model.User, error) {
 	query := `SELECT id, email, username, password_hash, full_name, bio, avatar_url,
 			  COALESCE(emoji_avatar, ''), is_admin, COALESCE(is_online, 0), last_seen,
-			  COALESCE(show_last_seen, 'all'), COALESCE(allow_messages_from, 'all'), firebase_uid, created_at 
+			  COALESCE(show_last_seen, 'all'), COALESCE(allow_messages_from, 'all'),
+			  COALESCE(private_account, TRUE), COALESCE(read_receipts, TRUE), firebase_uid, created_at 
 			  FROM users WHERE email = ?`
 	user := &model.User{}
 	err := r.db.QueryRow(query, email).Scan(
 		&user.ID, &user.Email, &user.Username, &user.PasswordHash,
 		&user.FullName, &user.Bio, &user.AvatarURL, &user.EmojiAvatar, &user.IsAdmin,
 		&user.IsOnline, &user.LastSeen, &user.ShowLastSeen, &user.AllowMessagesFrom,
-		&user.FirebaseUID, &user.CreatedAt,
+		&user.PrivateAccount, &user.ReadReceipts, &user.FirebaseUID, &user.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, errors.New("user not found")
@@ -66,14 +69,15 @@ func (r *UserRepository) GetByEmail(email string) (*model.User, error) {
 func (r *UserRepository) GetByUsername(username string) (*model.User, error) {
 	query := `SELECT id, email, username, password_hash, full_name, bio, avatar_url,
 			  COALESCE(emoji_avatar, ''), is_admin, COALESCE(is_online, 0), last_seen,
-			  COALESCE(show_last_seen, 'all'), COALESCE(allow_messages_from, 'all'), firebase_uid, created_at 
+			  COALESCE(show_last_seen, 'all'), COALESCE(allow_messages_from, 'all'),
+			  COALESCE(private_account, TRUE), COALESCE(read_receipts, TRUE), firebase_uid, created_at 
 			  FROM users WHERE username = ?`
 	user := &model.User{}
 	err := r.db.QueryRow(query, username).Scan(
 		&user.ID, &user.Email, &user.Username, &user.PasswordHash,
 		&user.FullName, &user.Bio, &user.AvatarURL, &user.EmojiAvatar, &user.IsAdmin,
 		&user.IsOnline, &user.LastSeen, &user.ShowLastSeen, &user.AllowMessagesFrom,
-		&user.FirebaseUID, &user.CreatedAt,
+		&user.PrivateAccount, &user.ReadReceipts, &user.FirebaseUID, &user.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, errors.New("user not found")
@@ -84,14 +88,15 @@ func (r *UserRepository) GetByUsername(username string) (*model.User, error) {
 func (r *UserRepository) GetByFirebaseUID(uid string) (*model.User, error) {
 	query := `SELECT id, email, username, password_hash, full_name, bio, avatar_url,
 			  COALESCE(emoji_avatar, ''), is_admin, COALESCE(is_online, 0), last_seen,
-			  COALESCE(show_last_seen, 'all'), COALESCE(allow_messages_from, 'all'), firebase_uid, created_at 
+			  COALESCE(show_last_seen, 'all'), COALESCE(allow_messages_from, 'all'),
+			  COALESCE(private_account, TRUE), COALESCE(read_receipts, TRUE), firebase_uid, created_at 
 			  FROM users WHERE firebase_uid = ?`
 	user := &model.User{}
 	err := r.db.QueryRow(query, uid).Scan(
 		&user.ID, &user.Email, &user.Username, &user.PasswordHash,
 		&user.FullName, &user.Bio, &user.AvatarURL, &user.EmojiAvatar, &user.IsAdmin,
 		&user.IsOnline, &user.LastSeen, &user.ShowLastSeen, &user.AllowMessagesFrom,
-		&user.FirebaseUID, &user.CreatedAt,
+		&user.PrivateAccount, &user.ReadReceipts, &user.FirebaseUID, &user.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, errors.New("user not found")
@@ -112,8 +117,26 @@ func (r *UserRepository) UpdateOnlineStatus(id int64, isOnline bool) error {
 }
 
 func (r *UserRepository) UpdatePrivacySettings(id int64, settings *model.UserPrivacySettings) error {
-	query := `UPDATE users SET show_last_seen = ?, allow_messages_from = ? WHERE id = ?`
-	_, err := r.db.Exec(query, settings.ShowLastSeen, settings.AllowMessagesFrom, id)
+	query := `UPDATE users SET show_last_seen = ?, allow_messages_from = ?,
+			  private_account = COALESCE(?, private_account), read_receipts = COALESCE(?, read_receipts) WHERE id = ?`
+	_, err := r.db.Exec(query, settings.ShowLastSeen, settings.AllowMessagesFrom, settings.PrivateAccount,
+		settings.ReadReceipts, id)
+	return err
+}
+
+func (r *UserRepository) GetPreferences(id int64) (*model.UserPreferences, error) {
+	query := `SELECT COALESCE(sensitive_content, 'hide') FROM users WHERE id = ?`
+	prefs := &model.UserPreferences{}
+	err := r.db.QueryRow(query, id).Scan(&prefs.SensitiveContent)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("user not found")
+	}
+	return prefs, err
+}
+
+func (r *UserRepository) UpdatePreferences(id int64, prefs *model.UserPreferences) error {
+	query := `UPDATE users SET sensitive_content = ? WHERE id = ?`
+	_, err := r.db.Exec(query, prefs.SensitiveContent, id)
 	return err
 }
 
@@ -136,10 +159,45 @@ func (r *UserRepository) Delete(id int64) error {
 }
 
 func (r *UserRepository) Search(searchTerm string, limit int) ([]*model.User, error) {
+	match := FTSMatch(searchTerm)
+	if match == "" {
+		return nil, nil
+	}
+
+	query := `SELECT u.id, u.email, u.username, u.full_name, u.bio, u.avatar_url, COALESCE(u.emoji_avatar, ''), u.is_admin, u.created_at 
+			  FROM users_fts
+			  INNER JOIN users u ON u.id = users_fts.rowid
+			  WHERE users_fts MATCH ?
+			  ORDER BY bm25(users_fts, 10.0, 5.0, 0.0) LIMIT ?`
+	rows, err := r.db.Query(query, "{username full_name} : ("+match+")", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*model.User
+	for rows.Next() {
+		user := &model.User{}
+		err := rows.Scan(&user.ID, &user.Email, &user.Username, &user.FullName,
+			&user.Bio, &user.AvatarURL, &user.EmojiAvatar, &user.IsAdmin, &user.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (r *UserRepository) SearchByUsernamePrefix(prefix string, viewerID int64, limit int) ([]*model.User, error) {
 	query := `SELECT id, email, username, full_name, bio, avatar_url, COALESCE(emoji_avatar, ''), is_admin, created_at 
-			  FROM users WHERE username LIKE ? OR full_name LIKE ? LIMIT ?`
-	pattern := "%" + searchTerm + "%"
-	rows, err := r.db.Query(query, pattern, pattern, limit)
+			  FROM users
+			  WHERE username LIKE ? ESCAPE '\' AND id NOT IN (
+				SELECT CASE WHEN requester_id = ? THEN addressee_id ELSE requester_id END
+				FROM friendships WHERE (requester_id = ? OR addressee_id = ?) AND status = 'blocked'
+			  )
+			  ORDER BY length(username) ASC, username ASC LIMIT ?`
+	pattern := escapeLike(prefix) + "%"
+	rows, err := r.db.Query(query, pattern, viewerID, viewerID, viewerID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -219,3 +277,7 @@ func (r *UserRepository) LinkFirebaseUID(id int64, uid string) error {
 func (r *UserRepository) GetUsersWithEmoji(emojiID string) ([]*model.User, error) {
 	return r.GetByEmojiAvatar(emojiID)
 }
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
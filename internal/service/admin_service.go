@@ -10,6 +10,7 @@ type AdminService struct {
 	reportRepo  *repository.ReportRepository
 	postRepo    *repository.PostRepository
 	commentRepo *repository.CommentRepository
+	groupRepo   *repository.GroupRepository
 	userRepo    *repository.UserRepository
 	statsRepo   *repository.StatsRepository
 	notifQueue  chan *model.Notification
@@ -19,6 +20,7 @@ func NewAdminService(
 	reportRepo *repository.ReportRepository,
 	postRepo *repository.PostRepository,
 	commentRepo *repository.CommentRepository,
+	groupRepo *repository.GroupRepository,
 	userRepo *repository.UserRepository,
 	statsRepo *repository.StatsRepository,
 	notifQueue chan *model.Notification,
@@ -27,6 +29,7 @@ func NewAdminService(
 		reportRepo:  reportRepo,
 		postRepo:    postRepo,
 		commentRepo: commentRepo,
+		groupRepo:   groupRepo,
 		userRepo:    userRepo,
 		statsRepo:   statsRepo,
 		notifQueue:  notifQueue,
@@ -42,6 +45,22 @@ func (s *AdminService) CreateReport(reporterID int64, create *model.ReportCreate
 		Status:     model.ReportStatusPending,
 	}
 
+	// Pin the revision being reported so a later edit cannot hide it from
+	// moderators. A plain repost is judged by its original.
+	if create.TargetType == model.ReportTargetPost {
+		post, err := s.postRepo.GetByID(create.TargetID)
+		if err != nil {
+			return err
+		}
+		postID := post.ID
+		if post.RepostOfID != nil {
+			postID = *post.RepostOfID
+		}
+		if revisionID, err := s.postRepo.GetLatestRevisionID(postID); err == nil {
+			report.RevisionID = &revisionID
+		}
+	}
+
 	_, err := s.reportRepo.Create(report)
 	return err
 }
@@ -55,19 +74,73 @@ func (s *AdminService) GetReports(status model.ReportStatus) ([]*model.Report, e
 	for _, report := range reports {
 		reporter, _ := s.userRepo.GetByID(report.ReporterID)
 		report.Reporter = reporter
+		if report.RevisionID != nil {
+			report.Revision, _ = s.postRepo.GetRevisionByID(*report.RevisionID)
+		}
 	}
 
 	return reports, nil
 }
 
-func (s *AdminService) ReviewReport(reportID int64, status model.ReportStatus) error {
+// ReviewReport updates a report's status, optionally flagging the reported
+// post at the same time.
+func (s *AdminService) ReviewReport(reportID int64, status model.ReportStatus, flags *model.ContentFlags) error {
 	if status != model.ReportStatusReviewed && status != model.ReportStatusResolved {
 		return errors.New("invalid status")
 	}
 
+	if flags != nil {
+		report, err := s.reportRepo.GetByID(reportID)
+		if err != nil {
+			return err
+		}
+		if report.TargetType != model.ReportTargetPost {
+			return errors.New("flags can only be applied to posts")
+		}
+		if err := s.SetPostFlags(report.TargetID, flags); err != nil {
+			return err
+		}
+	}
+
 	return s.reportRepo.UpdateStatus(reportID, status)
 }
 
+// SetPostFlags applies or clears moderator flags on a post, or on the
+// original of a repost. Flagged posts are locked against author changes
+// until both flags are cleared.
+func (s *AdminService) SetPostFlags(postID int64, flags *model.ContentFlags) error {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return err
+	}
+	if post.RepostOfID != nil {
+		if post, err = s.postRepo.GetByID(*post.RepostOfID); err != nil {
+			return err
+		}
+	}
+
+	contentWarning, sensitive, err := applyContentFlags(*flags, post.ContentWarning, post.Sensitive)
+	if err != nil {
+		return err
+	}
+
+	return s.postRepo.SetFlags(post.ID, contentWarning, sensitive, contentWarning != "" || sensitive)
+}
+
+func (s *AdminService) SetGroupPostFlags(postID int64, flags *model.ContentFlags) error {
+	post, err := s.groupRepo.GetPostByID(postID)
+	if err != nil {
+		return err
+	}
+
+	contentWarning, sensitive, err := applyContentFlags(*flags, post.ContentWarning, post.Sensitive)
+	if err != nil {
+		return err
+	}
+
+	return s.groupRepo.SetPostFlags(post.ID, contentWarning, sensitive)
+}
+
 func (s *AdminService) DeleteContent(targetType model.ReportTargetType, targetID int64) error {
 	switch targetType {
 	case model.ReportTargetPost:
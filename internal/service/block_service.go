@@ -0,0 +1,63 @@
+package service
+
+import (
+	"socialnet/internal/model"
+	"socialnet/internal/repository"
+)
+
+// BlockService is the single place that decides whether two users have
+// blocked each other. A blocked pair should see each other as if the other
+// account did not exist, so callers answer with "not found" errors.
+type BlockService struct {
+	friendRepo *repository.FriendshipRepository
+}
+
+func NewBlockService(friendRepo *repository.FriendshipRepository) *BlockService {
+	return &BlockService{friendRepo: friendRepo}
+}
+
+// IsBlocked reports whether either user has blocked the other. Lookup errors
+// count as blocked so a failure never exposes content.
+func (s *BlockService) IsBlocked(userID1, userID2 int64) bool {
+	if userID1 == userID2 {
+		return false
+	}
+
+	blocked, err := s.friendRepo.IsBlocked(userID1, userID2)
+	return err != nil || blocked
+}
+
+// BlockedIDs returns everyone userID has blocked or been blocked by, for
+// filtering lists. Callers fail the request on error rather than show an
+// unfiltered list.
+func (s *BlockService) BlockedIDs(userID int64) (map[int64]bool, error) {
+	ids, err := s.friendRepo.GetBlockedIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	blocked := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		blocked[id] = true
+	}
+	return blocked, nil
+}
+
+// FilterUsers drops the users blocked in either direction with viewerID.
+func (s *BlockService) FilterUsers(users []*model.User, viewerID int64) ([]*model.User, error) {
+	blocked, err := s.BlockedIDs(viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocked) == 0 {
+		return users, nil
+	}
+
+	visible := users[:0]
+	for _, user := range users {
+		if !blocked[user.ID] {
+			visible = append(visible, user)
+		}
+	}
+	return visible, nil
+}
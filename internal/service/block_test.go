@@ -0,0 +1,356 @@
+package service
+
+import (
+	"socialnet/internal/model"
+	"testing"
+)
+
+func userIDs(users []*model.User) []int64 {
+	ids := make([]int64, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
+
+func TestBlockIsOneWayUntilBothBlock(t *testing.T) {
+	e := newTestEnv(t)
+	alice, bob := e.createUser("alice"), e.createUser("bob")
+
+	e.block(alice, bob)
+	if !e.blocks.IsBlocked(bob, alice) {
+		t.Fatal("block should apply in both directions")
+	}
+	if err := e.social.UnblockUser(bob, alice); err == nil {
+		t.Fatal("the blocked user must not be able to unblock")
+	}
+	if err := e.social.SendFriendRequest(bob, alice); err == nil || err.Error() != "user not found" {
+		t.Fatalf("friend request to a blocker: got %v, want user not found", err)
+	}
+
+	blocked, _ := e.social.GetBlockedUsers(bob)
+	if len(blocked) != 0 {
+		t.Fatalf("bob has blocked nobody, got %v", userIDs(blocked))
+	}
+	blocked, _ = e.social.GetBlockedUsers(alice)
+	if ids := userIDs(blocked); len(ids) != 1 || ids[0] != bob {
+		t.Fatalf("alice's blocked list = %v, want [%d]", ids, bob)
+	}
+}
+
+func TestMutualBlockSurvivesOneSidedUnblock(t *testing.T) {
+	e := newTestEnv(t)
+	alice, bob := e.createUser("alice"), e.createUser("bob")
+
+	e.block(alice, bob)
+	e.block(bob, alice)
+
+	blocked, _ := e.social.GetBlockedUsers(bob)
+	if ids := userIDs(blocked); len(ids) != 1 || ids[0] != alice {
+		t.Fatalf("bob's blocked list = %v, want [%d]", ids, alice)
+	}
+
+	if err := e.social.UnblockUser(alice, bob); err != nil {
+		t.Fatal(err)
+	}
+	if !e.blocks.IsBlocked(alice, bob) {
+		t.Fatal("bob's block should still stand after alice unblocks")
+	}
+	if err := e.social.UnblockUser(alice, bob); err == nil || err.Error() != "user is not blocked" {
+		t.Fatalf("second unblock by alice: got %v, want user is not blocked", err)
+	}
+	blocked, _ = e.social.GetBlockedUsers(alice)
+	if len(blocked) != 0 {
+		t.Fatalf("alice's blocked list = %v, want empty", userIDs(blocked))
+	}
+	blocked, _ = e.social.GetBlockedUsers(bob)
+	if len(blocked) != 1 {
+		t.Fatal("bob's blocked list lost his block")
+	}
+
+	if err := e.social.UnblockUser(bob, alice); err != nil {
+		t.Fatal(err)
+	}
+	if e.blocks.IsBlocked(alice, bob) {
+		t.Fatal("pair should be unblocked once both have unblocked")
+	}
+	if err := e.social.SendFriendRequest(bob, alice); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBlockReplacesFriendship(t *testing.T) {
+	e := newTestEnv(t)
+	alice, bob := e.createUser("alice"), e.createUser("bob")
+	e.befriend(alice, bob)
+
+	e.block(bob, alice)
+
+	friends, _ := e.social.GetFriends(alice)
+	if len(friends) != 0 {
+		t.Fatalf("friends after block = %v, want none", userIDs(friends))
+	}
+	if err := e.social.UnblockUser(bob, alice); err != nil {
+		t.Fatal(err)
+	}
+	friends, _ = e.social.GetFriends(alice)
+	if len(friends) != 0 {
+		t.Fatal("unblocking must not restore the friendship")
+	}
+}
+
+func TestBlockHidesProfilesAndUserSearch(t *testing.T) {
+	e := newTestEnv(t)
+	alice, bob := e.createUser("alice"), e.createUser("bobby")
+	e.block(bob, alice)
+
+	if _, err := e.users.GetPublicProfile(bob, alice); err == nil {
+		t.Fatal("blocked user can see the blocker's profile")
+	}
+	if _, err := e.users.GetPublicProfile(alice, bob); err == nil {
+		t.Fatal("blocker can see the blocked user's profile")
+	}
+	if users, _ := e.users.SearchUsers("bob", alice); len(users) != 0 {
+		t.Fatalf("search found %v across a block", userIDs(users))
+	}
+	if users, _ := e.users.AutocompleteUsers("@bob", alice); len(users) != 0 {
+		t.Fatal("autocomplete found a user across a block")
+	}
+
+	if err := e.social.UnblockUser(bob, alice); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.users.GetPublicProfile(bob, alice); err != nil {
+		t.Fatalf("profile after unblock: %v", err)
+	}
+	if users, _ := e.users.AutocompleteUsers("bob", alice); len(users) != 1 {
+		t.Fatal("autocomplete should find the user after unblock")
+	}
+}
+
+func TestBlockHidesPostsCommentsAndReactions(t *testing.T) {
+	e := newTestEnv(t)
+	alice, bob, carol := e.createUser("alice"), e.createUser("bob"), e.createUser("carol")
+	e.makePublic(alice)
+	post := e.createPost(alice, "hello world")
+
+	comment, err := e.social.CommentOnPost(post.ID, carol, &model.CommentCreate{Content: "first"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e.block(alice, bob)
+
+	if _, err := e.posts.GetPost(post.ID, bob); err == nil || err.Error() != "post not found" {
+		t.Fatalf("GetPost across a block: got %v, want post not found", err)
+	}
+	if _, err := e.social.CommentOnPost(post.ID, bob, &model.CommentCreate{Content: "hi"}); err == nil {
+		t.Fatal("blocked user could comment")
+	}
+	if err := e.reactions.React(model.ReactionTargetPost, post.ID, bob, "like"); err == nil {
+		t.Fatal("blocked user could react to the post")
+	}
+	if err := e.reactions.React(model.ReactionTargetComment, comment.ID, bob, "like"); err == nil {
+		t.Fatal("blocked user could react to a comment on the post")
+	}
+
+	// A comment by someone blocked stays in the thread as a placeholder.
+	e.block(carol, bob)
+	if err := e.social.UnblockUser(alice, bob); err != nil {
+		t.Fatal(err)
+	}
+	comments, err := e.social.GetComments(post.ID, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 1 || !comments[0].Hidden || comments[0].Content != "" || comments[0].Author != nil {
+		t.Fatalf("blocked commenter's comment should be a hidden placeholder, got %+v", comments[0])
+	}
+}
+
+func TestPrivatePostNeedsAudience(t *testing.T) {
+	e := newTestEnv(t)
+	alice, bob, carol := e.createUser("alice"), e.createUser("bob"), e.createUser("carol")
+	e.befriend(alice, carol)
+	post := e.createPost(alice, "friends only")
+
+	if _, err := e.posts.GetPost(post.ID, bob); err == nil {
+		t.Fatal("outsider can view a private account's post")
+	}
+	if _, err := e.social.CommentOnPost(post.ID, bob, &model.CommentCreate{Content: "hi"}); err == nil {
+		t.Fatal("outsider can comment on a private account's post")
+	}
+	if err := e.reactions.React(model.ReactionTargetPost, post.ID, bob, "like"); err == nil {
+		t.Fatal("outsider can react to a private account's post")
+	}
+	if _, err := e.posts.GetPost(post.ID, carol); err != nil {
+		t.Fatalf("friend cannot view the post: %v", err)
+	}
+}
+
+func TestBlockClosesConversations(t *testing.T) {
+	e := newTestEnv(t)
+	alice, bob := e.createUser("alice"), e.createUser("bob")
+	e.befriend(alice, bob)
+
+	conversation, err := e.messages.StartConversation(alice, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.messages.SendMessage(conversation.ID, alice, &model.MessageCreate{Body: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+
+	e.block(bob, alice)
+	e.block(alice, bob)
+
+	if _, err := e.messages.StartConversation(alice, bob); err == nil {
+		t.Fatal("could start a conversation across a block")
+	}
+	if _, err := e.messages.SendMessage(conversation.ID, alice, &model.MessageCreate{Body: "hi again"}); err == nil {
+		t.Fatal("could message across a block")
+	}
+	if conversations, _ := e.messages.GetConversations(bob); len(conversations) != 0 {
+		t.Fatal("conversation with a blocked user is still listed")
+	}
+
+	// Alice lifting her block leaves bob's in place.
+	if err := e.social.UnblockUser(alice, bob); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.messages.StartConversation(alice, bob); err == nil {
+		t.Fatal("could start a conversation while the other block stands")
+	}
+
+	if err := e.social.UnblockUser(bob, alice); err != nil {
+		t.Fatal(err)
+	}
+	if conversations, _ := e.messages.GetConversations(bob); len(conversations) != 1 {
+		t.Fatal("conversation should be listed again once both blocks are gone")
+	}
+}
+
+func TestBlockHidesGroupPostsAndMembers(t *testing.T) {
+	e := newTestEnv(t)
+	alice, bob := e.createUser("alice"), e.createUser("bob")
+
+	group, err := e.groups.CreateGroup(alice, &model.GroupCreate{Title: "Gophers"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.groups.JoinGroup(group.ID, bob); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.groups.PostToGroup(group.ID, bob, &model.GroupPostCreate{Content: "from bob"}); err != nil {
+		t.Fatal(err)
+	}
+
+	e.block(alice, bob)
+
+	posts, err := e.groups.GetGroupPosts(group.ID, alice, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 0 {
+		t.Fatal("group posts by a blocked user are listed")
+	}
+	members, err := e.groups.GetGroupMembers(group.ID, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, member := range members {
+		if member.UserID == alice {
+			t.Fatal("blocker is listed among group members")
+		}
+	}
+}
+
+func TestSearchLeavesOutBlockedUsers(t *testing.T) {
+	e := newTestEnv(t)
+	alice, bob := e.createUser("alice"), e.createUser("bob")
+	e.makePublic(alice)
+	e.makePublic(bob)
+	e.createPost(alice, "golang tips")
+	e.createPost(bob, "golang tricks")
+
+	results, err := e.search.Search(alice, &model.SearchFilter{Query: "golang"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results before the block, want 2", len(results))
+	}
+	for _, result := range results {
+		if result.Post == nil || result.Post.Status != model.PostStatusPublished || result.Post.ContentHTML == "" {
+			t.Fatalf("post hit not hydrated: %+v", result.Post)
+		}
+	}
+
+	e.block(bob, alice)
+
+	results, err = e.search.Search(alice, &model.SearchFilter{Query: "golang"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Post.UserID != alice {
+		t.Fatalf("search across a block returned %d results", len(results))
+	}
+	results, err = e.search.Search(alice, &model.SearchFilter{Query: "bob", Type: model.SearchTypeUser})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 0 {
+		t.Fatal("user search found a user across a block")
+	}
+}
+
+func TestMentionsSkipBlockedAndOutsideAudience(t *testing.T) {
+	e := newTestEnv(t)
+	alice, bob, carol, dave := e.createUser("alice"), e.createUser("bob"), e.createUser("carol"), e.createUser("dave")
+	e.befriend(alice, carol)
+	e.befriend(alice, dave)
+	e.drainNotifications()
+
+	e.block(dave, alice)
+	e.createPost(alice, "hi @bob @carol @dave")
+
+	var mentioned []int64
+	for _, n := range e.drainNotifications() {
+		if n.Type == model.NotificationMention {
+			mentioned = append(mentioned, n.UserID)
+		}
+	}
+	if len(mentioned) != 1 || mentioned[0] != carol {
+		t.Fatalf("mention notifications went to %v, want only carol (%d); bob is %d", mentioned, carol, bob)
+	}
+}
+
+func TestBlockLookupFailureFailsLists(t *testing.T) {
+	e := newTestEnv(t)
+	alice, bob := e.createUser("alice"), e.createUser("bob")
+
+	group, err := e.groups.CreateGroup(alice, &model.GroupCreate{Title: "Gophers"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.groups.JoinGroup(group.ID, bob); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := e.db.Exec(`DROP TABLE friendships`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := e.blocks.BlockedIDs(alice); err == nil {
+		t.Fatal("BlockedIDs hid a failed lookup")
+	}
+	if members, err := e.groups.GetGroupMembers(group.ID, alice); err == nil {
+		t.Fatalf("group members listed without block filtering: %d members", len(members))
+	}
+	if _, err := e.groups.GetGroupPosts(group.ID, alice, false); err == nil {
+		t.Fatal("group posts listed without block filtering")
+	}
+	if users, err := e.users.SearchUsers("bob", alice); err == nil {
+		t.Fatalf("user search returned %v without block filtering", userIDs(users))
+	}
+}
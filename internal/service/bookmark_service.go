@@ -0,0 +1,137 @@
+package service
+
+import (
+	"errors"
+	"socialnet/internal/model"
+	"socialnet/internal/repository"
+	"strings"
+	"unicode/utf8"
+)
+
+type BookmarkService struct {
+	bookmarkRepo *repository.BookmarkRepository
+	postRepo     *repository.PostRepository
+}
+
+func NewBookmarkService(bookmarkRepo *repository.BookmarkRepository, postRepo *repository.PostRepository) *BookmarkService {
+	return &BookmarkService{
+		bookmarkRepo: bookmarkRepo,
+		postRepo:     postRepo,
+	}
+}
+
+func (s *BookmarkService) Bookmark(userID, postID int64, create *model.BookmarkCreate) error {
+	visible, err := s.postRepo.IsVisibleTo(postID, userID)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return errors.New("post not found")
+	}
+
+	if create.CollectionID != nil {
+		if _, err := s.getOwnCollection(*create.CollectionID, userID); err != nil {
+			return err
+		}
+	}
+
+	return s.bookmarkRepo.Set(userID, postID, create.CollectionID)
+}
+
+func (s *BookmarkService) RemoveBookmark(userID, postID int64) error {
+	return s.bookmarkRepo.Delete(userID, postID)
+}
+
+func (s *BookmarkService) IsBookmarked(userID, postID int64) bool {
+	bookmarked, _ := s.bookmarkRepo.IsBookmarked(userID, postID)
+	return bookmarked
+}
+
+// GetBookmarkedPostIDs pages through the user's bookmarks, optionally within
+// one collection. Bookmarks on posts the user can no longer see are removed
+// first so they never show up as gaps.
+func (s *BookmarkService) GetBookmarkedPostIDs(userID int64, collectionID *int64, limit, offset int) ([]int64, error) {
+	if collectionID != nil {
+		if _, err := s.getOwnCollection(*collectionID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	if err := s.bookmarkRepo.DeleteInvisible(userID); err != nil {
+		return nil, err
+	}
+
+	return s.bookmarkRepo.GetPostIDs(userID, collectionID, limit, offset)
+}
+
+func (s *BookmarkService) CreateCollection(userID int64, create *model.BookmarkCollectionCreate) (*model.BookmarkCollection, error) {
+	name, err := validateCollectionName(create.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.bookmarkRepo.CreateCollection(userID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.bookmarkRepo.GetCollection(id)
+}
+
+func (s *BookmarkService) GetCollections(userID int64) ([]*model.BookmarkCollection, error) {
+	return s.bookmarkRepo.GetCollections(userID)
+}
+
+func (s *BookmarkService) RenameCollection(id, userID int64, update *model.BookmarkCollectionCreate) (*model.BookmarkCollection, error) {
+	name, err := validateCollectionName(update.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getOwnCollection(id, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.bookmarkRepo.RenameCollection(id, name); err != nil {
+		return nil, err
+	}
+
+	return s.bookmarkRepo.GetCollection(id)
+}
+
+// DeleteCollection removes a collection. Its bookmarks are kept, unfiled.
+func (s *BookmarkService) DeleteCollection(id, userID int64) error {
+	if _, err := s.getOwnCollection(id, userID); err != nil {
+		return err
+	}
+	return s.bookmarkRepo.DeleteCollection(id)
+}
+
+func (s *BookmarkService) getOwnCollection(id, userID int64) (*model.BookmarkCollection, error) {
+	collection, err := s.bookmarkRepo.GetCollection(id)
+	if err != nil {
+		return nil, err
+	}
+	if collection.UserID != userID {
+		return nil, errors.New("collection not found")
+	}
+	return collection, nil
+}
+
+func validateCollectionName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("collection name is required")
+	}
+	if utf8.RuneCountInString(name) > model.MaxCollectionNameLength {
+		return "", errors.New("collection name is too long")
+	}
+	return name, nil
+}
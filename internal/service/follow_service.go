@@ -0,0 +1,260 @@
+package service
+
+import (
+	"errors"
+	"socialnet/internal/model"
+	"socialnet/internal/repository"
+)
+
+type FollowService struct {
+	followRepo   *repository.FollowRepository
+	userRepo     *repository.UserRepository
+	friendRepo   *repository.FriendshipRepository
+	blockService *BlockService
+	notifQueue   chan *model.Notification
+}
+
+func NewFollowService(followRepo *repository.FollowRepository, userRepo *repository.UserRepository,
+	friendRepo *repository.FriendshipRepository, blockService *BlockService,
+	notifQueue chan *model.Notification) *FollowService {
+	return &FollowService{
+		followRepo:   followRepo,
+		userRepo:     userRepo,
+		friendRepo:   friendRepo,
+		blockService: blockService,
+		notifQueue:   notifQueue,
+	}
+}
+
+// Follow follows a public account straight away and sends a request to a
+// private one.
+func (s *FollowService) Follow(followerID, followeeID int64) (*model.Follow, error) {
+	if followerID == followeeID {
+		return nil, errors.New("cannot follow yourself")
+	}
+
+	followee, err := s.userRepo.GetByID(followeeID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if s.blockService.IsBlocked(followerID, followeeID) {
+		return nil, errors.New("user not found")
+	}
+
+	existing, err := s.followRepo.Get(followerID, followeeID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.Status == model.FollowPending {
+			return nil, errors.New("follow request already sent")
+		}
+		return nil, errors.New("already following")
+	}
+
+	status := model.FollowAccepted
+	if followee.PrivateAccount {
+		status = model.FollowPending
+	}
+
+	id, err := s.followRepo.Create(followerID, followeeID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	follower, _ := s.userRepo.GetByID(followerID)
+	if status == model.FollowAccepted {
+		s.notifQueue <- &model.Notification{
+			UserID:   followeeID,
+			Type:     model.NotificationFollow,
+			TargetID: followerID,
+			Message:  follower.Username + " started following you",
+			ActorID:  followerID,
+		}
+	} else {
+		s.notifQueue <- &model.Notification{
+			UserID:   followeeID,
+			Type:     model.NotificationFollowRequest,
+			TargetID: id,
+			Message:  follower.Username + " requested to follow you",
+			ActorID:  followerID,
+		}
+	}
+
+	return s.followRepo.GetByID(id)
+}
+
+// Unfollow stops following an account or withdraws a pending request.
+func (s *FollowService) Unfollow(followerID, followeeID int64) error {
+	follow, err := s.followRepo.Get(followerID, followeeID)
+	if err != nil {
+		return err
+	}
+	if follow == nil {
+		return errors.New("not following")
+	}
+
+	return s.followRepo.Delete(follow.ID)
+}
+
+func (s *FollowService) GetPendingRequests(userID int64) ([]*model.Follow, error) {
+	requests, err := s.followRepo.GetPendingRequests(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, request := range requests {
+		if follower, err := s.userRepo.GetByID(request.FollowerID); err == nil {
+			request.Follower = follower.ToPublic(userID, false)
+		}
+	}
+
+	return requests, nil
+}
+
+func (s *FollowService) AcceptRequest(requestID, userID int64) error {
+	request, err := s.getPendingRequest(requestID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.followRepo.Accept(request.ID); err != nil {
+		return err
+	}
+
+	s.notifyAccepted(request.FollowerID, userID)
+	return nil
+}
+
+func (s *FollowService) DeclineRequest(requestID, userID int64) error {
+	request, err := s.getPendingRequest(requestID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.followRepo.Delete(request.ID)
+}
+
+// AcceptPendingRequests approves everyone waiting to follow the user, for
+// when their account is made public.
+func (s *FollowService) AcceptPendingRequests(userID int64) error {
+	followerIDs, err := s.followRepo.AcceptAllPending(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, followerID := range followerIDs {
+		s.notifyAccepted(followerID, userID)
+	}
+	return nil
+}
+
+func (s *FollowService) GetFollowers(targetID, viewerID int64, limit, offset int) ([]*model.UserPublic, error) {
+	if err := s.checkConnectionsVisible(targetID, viewerID); err != nil {
+		return nil, err
+	}
+
+	limit, offset = followPage(limit, offset)
+	users, err := s.followRepo.GetFollowers(targetID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if users, err = s.blockService.FilterUsers(users, viewerID); err != nil {
+		return nil, err
+	}
+	return toPublicUsers(users, viewerID), nil
+}
+
+func (s *FollowService) GetFollowing(targetID, viewerID int64, limit, offset int) ([]*model.UserPublic, error) {
+	if err := s.checkConnectionsVisible(targetID, viewerID); err != nil {
+		return nil, err
+	}
+
+	limit, offset = followPage(limit, offset)
+	users, err := s.followRepo.GetFollowing(targetID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if users, err = s.blockService.FilterUsers(users, viewerID); err != nil {
+		return nil, err
+	}
+	return toPublicUsers(users, viewerID), nil
+}
+
+// Describe fills in the follow counts of a profile and whether the viewer
+// follows it.
+func (s *FollowService) Describe(profile *model.UserPublic, viewerID int64) {
+	profile.FollowerCount, profile.FollowingCount, _ = s.followRepo.GetCounts(profile.ID)
+
+	if viewerID != profile.ID {
+		if follow, _ := s.followRepo.Get(viewerID, profile.ID); follow != nil {
+			profile.FollowStatus = follow.Status
+		}
+	}
+}
+
+// checkConnectionsVisible hides the follower lists of a private account from
+// anyone outside its audience.
+func (s *FollowService) checkConnectionsVisible(targetID, viewerID int64) error {
+	target, err := s.userRepo.GetByID(targetID)
+	if err != nil || s.blockService.IsBlocked(targetID, viewerID) {
+		return errors.New("user not found")
+	}
+	if !target.PrivateAccount || targetID == viewerID {
+		return nil
+	}
+
+	if friends, _ := s.friendRepo.AreFriends(targetID, viewerID); friends {
+		return nil
+	}
+	if follow, _ := s.followRepo.Get(viewerID, targetID); follow != nil && follow.Status == model.FollowAccepted {
+		return nil
+	}
+
+	return errors.New("this account is private")
+}
+
+func (s *FollowService) getPendingRequest(requestID, userID int64) (*model.Follow, error) {
+	request, err := s.followRepo.GetByID(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.FolloweeID != userID || request.Status != model.FollowPending {
+		return nil, errors.New("follow request not found")
+	}
+	return request, nil
+}
+
+func (s *FollowService) notifyAccepted(followerID, userID int64) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return
+	}
+
+	s.notifQueue <- &model.Notification{
+		UserID:   followerID,
+		Type:     model.NotificationFollowAccept,
+		TargetID: userID,
+		Message:  user.Username + " accepted your follow request",
+		ActorID:  userID,
+	}
+}
+
+func followPage(limit, offset int) (int, int) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+func toPublicUsers(users []*model.User, viewerID int64) []*model.UserPublic {
+	result := make([]*model.UserPublic, len(users))
+	for i, user := range users {
+		result[i] = user.ToPublic(viewerID, false)
+	}
+	return result
+}
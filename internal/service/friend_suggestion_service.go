@@ -0,0 +1,70 @@
+package service
+
+import (
+	"errors"
+	"socialnet/internal/model"
+	"socialnet/internal/repository"
+)
+
+// suggestionRefreshBatch bounds how many users one worker pass recomputes.
+const suggestionRefreshBatch = 200
+
+type FriendSuggestionService struct {
+	suggestionRepo *repository.FriendSuggestionRepository
+	userRepo       *repository.UserRepository
+	blockService   *BlockService
+}
+
+func NewFriendSuggestionService(suggestionRepo *repository.FriendSuggestionRepository,
+	userRepo *repository.UserRepository, blockService *BlockService) *FriendSuggestionService {
+	return &FriendSuggestionService{
+		suggestionRepo: suggestionRepo,
+		userRepo:       userRepo,
+		blockService:   blockService,
+	}
+}
+
+// GetSuggestions returns the precomputed suggestions for userID. Only a user
+// who has never had suggestions computed waits for them here; later changes
+// are picked up by the worker.
+func (s *FriendSuggestionService) GetSuggestions(userID int64, limit int) ([]*model.FriendSuggestion, error) {
+	if limit <= 0 || limit > 50 {
+		limit = 20
+	}
+
+	if pending, _ := s.suggestionRepo.NeverRefreshed(userID); pending {
+		if err := s.suggestionRepo.Refresh(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.suggestionRepo.GetForUser(userID, limit)
+}
+
+func (s *FriendSuggestionService) Dismiss(userID, dismissedID int64) error {
+	if userID == dismissedID {
+		return errors.New("cannot dismiss yourself")
+	}
+
+	if _, err := s.userRepo.GetByID(dismissedID); err != nil || s.blockService.IsBlocked(userID, dismissedID) {
+		return errors.New("user not found")
+	}
+
+	return s.suggestionRepo.Dismiss(userID, dismissedID)
+}
+
+// RefreshStale recomputes suggestions for users affected by recent changes
+// and returns how many were refreshed.
+func (s *FriendSuggestionService) RefreshStale() (int, error) {
+	ids, err := s.suggestionRepo.GetStale(suggestionRefreshBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, id := range ids {
+		if err := s.suggestionRepo.Refresh(id); err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
@@ -2,23 +2,33 @@ package service
 
 import (
 	"errors"
+	"socialnet/internal/markdown"
 	"socialnet/internal/model"
 	"socialnet/internal/repository"
 	"socialnet/internal/security"
 )
 
 type GroupService struct {
-	groupRepo  *repository.GroupRepository
-	userRepo   *repository.UserRepository
-	notifQueue chan *model.Notification
+	groupRepo        *repository.GroupRepository
+	userRepo         *repository.UserRepository
+	mentionService   *MentionService
+	mediaService     *MediaService
+	mutedWordService *MutedWordService
+	blockService     *BlockService
+	notifQueue       chan *model.Notification
 }
 
 func NewGroupService(groupRepo *repository.GroupRepository, userRepo *repository.UserRepository,
-	notifQueue chan *model.Notification) *GroupService {
+	mentionService *MentionService, mediaService *MediaService, mutedWordService *MutedWordService,
+	blockService *BlockService, notifQueue chan *model.Notification) *GroupService {
 	return &GroupService{
-		groupRepo:  groupRepo,
-		userRepo:   userRepo,
-		notifQueue: notifQueue,
+		groupRepo:        groupRepo,
+		userRepo:         userRepo,
+		mentionService:   mentionService,
+		mediaService:     mediaService,
+		mutedWordService: mutedWordService,
+		blockService:     blockService,
+		notifQueue:       notifQueue,
 	}
 }
 
@@ -96,11 +106,27 @@ func (s *GroupService) PostToGroup(groupID, userID int64, create *model.GroupPos
 		return nil, errors.New("must be a member to post")
 	}
 
+	if err := s.mediaService.ValidateMediaURL(userID, create.MediaURL); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.mediaService.ResolveAttachments(userID, create.Attachments)
+	if err != nil {
+		return nil, err
+	}
+
+	contentWarning, sensitive, err := applyContentFlags(create.ContentFlags, "", false)
+	if err != nil {
+		return nil, err
+	}
+
 	post := &model.GroupPost{
-		GroupID:  groupID,
-		UserID:   userID,
-		Content:  create.Content,
-		MediaURL: create.MediaURL,
+		GroupID:        groupID,
+		UserID:         userID,
+		Content:        create.Content,
+		MediaURL:       create.MediaURL,
+		ContentWarning: contentWarning,
+		Sensitive:      sensitive,
 	}
 
 	id, err := s.groupRepo.CreatePost(post)
@@ -108,6 +134,12 @@ func (s *GroupService) PostToGroup(groupID, userID int64, create *model.GroupPos
 		return nil, err
 	}
 
+	if len(attachments) > 0 {
+		if err := s.mediaService.SetAttachments(model.AttachmentTargetGroupPost, id, attachments); err != nil {
+			return nil, err
+		}
+	}
+
 	post, err = s.groupRepo.GetPostByID(id)
 	if err != nil {
 		return nil, err
@@ -115,11 +147,22 @@ func (s *GroupService) PostToGroup(groupID, userID int64, create *model.GroupPos
 
 	author, _ := s.userRepo.GetByID(post.UserID)
 	post.Author = author
+	post.ContentHTML = markdown.Render(post.Content)
+	post.Attachments = attachments
+
+	s.mentionService.ProcessMentions(userID, model.MentionTargetGroupPost, post.ID, groupID, post.Content,
+		func(mentionedID int64) bool {
+			isMember, _ := s.groupRepo.IsMember(groupID, mentionedID)
+			return isMember
+		})
 
 	return post, nil
 }
 
-func (s *GroupService) GetGroupPosts(groupID, userID int64) ([]*model.GroupPost, error) {
+// GetGroupPosts lists a group's posts. Posts by users blocked either way are
+// always left out; those matching the viewer's muted words are left out unless
+// includeMuted is set.
+func (s *GroupService) GetGroupPosts(groupID, userID int64, includeMuted bool) ([]*model.GroupPost, error) {
 	isMember, _ := s.groupRepo.IsMember(groupID, userID)
 	if !isMember {
 		return nil, errors.New("must be a member to view posts")
@@ -130,12 +173,32 @@ func (s *GroupService) GetGroupPosts(groupID, userID int64) ([]*model.GroupPost,
 		return nil, err
 	}
 
+	hide := hidesSensitive(s.userRepo, userID)
+	blocked, err := s.blockService.BlockedIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	visible := posts[:0]
 	for _, post := range posts {
+		if blocked[post.UserID] {
+			continue
+		}
+		if post.UserID != userID {
+			post.MutedWords = s.mutedWordService.Match(userID, post.Content, post.ContentWarning)
+		}
+		if post.MutedWords != nil && !includeMuted {
+			continue
+		}
+
 		author, _ := s.userRepo.GetByID(post.UserID)
 		post.Author = author
+		post.ContentHTML = markdown.Render(post.Content)
+		post.Attachments = s.mediaService.GetAttachments(model.AttachmentTargetGroupPost, post.ID)
+		post.Collapsed = (post.ContentWarning != "" || post.Sensitive) && post.UserID != userID && hide
+		visible = append(visible, post)
 	}
 
-	return posts, nil
+	return visible, nil
 }
 
 func (s *GroupService) GetUserGroups(userID int64) ([]*model.Group, error) {
@@ -168,12 +231,21 @@ func (s *GroupService) GetGroupMembers(groupID, userID int64) ([]*model.GroupMem
 		return nil, err
 	}
 
+	blocked, err := s.blockService.BlockedIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	visible := members[:0]
 	for _, member := range members {
+		if blocked[member.UserID] {
+			continue
+		}
 		user, _ := s.userRepo.GetByID(member.UserID)
 		member.User = user
+		visible = append(visible, member)
 	}
 
-	return members, nil
+	return visible, nil
 }
 
 func (s *GroupService) UpdateGroupSettings(groupID, userID int64, settings *model.GroupSettings) error {
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"socialnet/internal/model"
+	"socialnet/internal/repository"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	maxPreviewPageSize  = 1 << 20
+	maxPreviewImageSize = 5 << 20
+	maxPreviewURLLength = 2048
+	maxPreviewTitle     = 300
+	maxPreviewText      = 1000
+)
+
+var (
+	urlPattern = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `]+`)
+
+	previewImageTypes = map[string]string{
+		"image/jpeg": ".jpg",
+		"image/png":  ".png",
+		"image/gif":  ".gif",
+		"image/webp": ".webp",
+	}
+)
+
+// LinkPreviewService caches Open Graph style previews for the first link in
+// a post. Fetching happens on the link preview worker; requests only record
+// the URL and queue it.
+type LinkPreviewService struct {
+	previewRepo *repository.LinkPreviewRepository
+	client      *http.Client
+	uploadDir   string
+	queue       chan string
+}
+
+func NewLinkPreviewService(previewRepo *repository.LinkPreviewRepository, client *http.Client,
+	uploadDir string, queue chan string) *LinkPreviewService {
+	return &LinkPreviewService{
+		previewRepo: previewRepo,
+		client:      client,
+		uploadDir:   uploadDir,
+		queue:       queue,
+	}
+}
+
+// ExtractURL returns the first http(s) link in content, or "" if there is none.
+func ExtractURL(content string) string {
+	match := urlPattern.FindString(content)
+	match = strings.TrimRight(match, ".,;:!?)]}")
+	if match == "" || len(match) > maxPreviewURLLength {
+		return ""
+	}
+
+	parsed, err := url.Parse(match)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	return parsed.String()
+}
+
+// Request queues a preview fetch for the link in content unless one is
+// already cached. When the queue is full the URL stays pending and is picked
+// up by the worker's next sweep.
+func (s *LinkPreviewService) Request(content string) {
+	link := ExtractURL(content)
+	if link == "" {
+		return
+	}
+
+	created, err := s.previewRepo.CreatePending(link)
+	if err != nil || !created {
+		return
+	}
+
+	select {
+	case s.queue <- link:
+	default:
+	}
+}
+
+func (s *LinkPreviewService) GetPreview(content string) *model.LinkPreview {
+	link := ExtractURL(content)
+	if link == "" {
+		return nil
+	}
+
+	preview, err := s.previewRepo.GetByURL(link)
+	if err != nil || preview == nil || preview.Status != model.LinkPreviewReady {
+		return nil
+	}
+	return preview
+}
+
+// Process fetches a pending preview and stores the result. Failed fetches are
+// cached as well so a broken link is not retried on every post.
+func (s *LinkPreviewService) Process(link string) error {
+	preview, err := s.previewRepo.GetByURL(link)
+	if err != nil {
+		return err
+	}
+	if preview == nil || preview.Status != model.LinkPreviewPending {
+		return nil
+	}
+
+	fetched, err := s.fetch(link)
+	if err != nil {
+		log.Printf("Link preview for %s failed: %v", link, err)
+		preview.Status = model.LinkPreviewFailed
+		return s.previewRepo.Save(preview)
+	}
+
+	fetched.URL = link
+	fetched.Status = model.LinkPreviewReady
+	return s.previewRepo.Save(fetched)
+}
+
+// ProcessPending fetches a batch of pending previews and returns how many
+// were stored. A link that cannot be saved is logged and left pending for the
+// next sweep rather than holding up the rest of the batch.
+func (s *LinkPreviewService) ProcessPending() (int, error) {
+	links, err := s.previewRepo.GetPending(50)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, link := range links {
+		if err := s.Process(link); err != nil {
+			log.Printf("Failed to process link preview %s: %v", link, err)
+			continue
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+func (s *LinkPreviewService) fetch(link string) (*model.LinkPreview, error) {
+	req, err := http.NewRequest(http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "socialnet-link-preview/1.0")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, errors.New("not an html page")
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, maxPreviewPageSize), contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := parsePreview(body)
+	if preview.Title == "" && preview.Description == "" {
+		return nil, errors.New("page has no title or description")
+	}
+
+	if preview.ImageURL != "" {
+		image, err := resp.Request.URL.Parse(preview.ImageURL)
+		preview.ImageURL = ""
+		if err == nil && (image.Scheme == "http" || image.Scheme == "https") {
+			if local, err := s.storeImage(image.String()); err == nil {
+				preview.ImageURL = local
+			} else {
+				log.Printf("Link preview image %s skipped: %v", image, err)
+			}
+		}
+	}
+
+	return preview, nil
+}
+
+// storeImage downloads a preview image into the upload directory so clients
+// never load it from the linked site directly.
+func (s *LinkPreviewService) storeImage(link string) (string, error) {
+	if s.uploadDir == "" {
+		return "", errors.New("uploads are disabled")
+	}
+
+	resp, err := s.client.Get(link)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPreviewImageSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxPreviewImageSize {
+		return "", errors.New("image too large")
+	}
+
+	ext, ok := previewImageTypes[http.DetectContentType(data)]
+	if !ok {
+		return "", errors.New("unsupported image type")
+	}
+
+	sum := sha256.Sum256([]byte(link))
+	filename := "preview_" + hex.EncodeToString(sum[:8]) + ext
+	if err := os.WriteFile(filepath.Join(s.uploadDir, filename), data, 0644); err != nil {
+		return "", err
+	}
+	return "/uploads/" + filename, nil
+}
+
+// parsePreview reads the document head, preferring Open Graph tags over
+// Twitter cards over plain HTML.
+func parsePreview(r io.Reader) *model.LinkPreview {
+	meta := make(map[string]string)
+	var title string
+	inTitle := false
+
+	z := html.NewTokenizer(r)
+scan:
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			break scan
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			switch tok.Data {
+			case "body":
+				break scan
+			case "title":
+				inTitle = title == ""
+			case "meta":
+				var key, content string
+				for _, attr := range tok.Attr {
+					switch attr.Key {
+					case "property", "name":
+						if key == "" {
+							key = strings.ToLower(strings.TrimSpace(attr.Val))
+						}
+					case "content":
+						content = strings.TrimSpace(attr.Val)
+					}
+				}
+				if key != "" && content != "" && meta[key] == "" {
+					meta[key] = content
+				}
+			}
+		case html.TextToken:
+			if inTitle {
+				title += string(z.Text())
+			}
+		case html.EndTagToken:
+			tok := z.Token()
+			if tok.Data == "title" {
+				inTitle = false
+			} else if tok.Data == "head" {
+				break scan
+			}
+		}
+	}
+
+	first := func(values ...string) string {
+		for _, v := range values {
+			if v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	return &model.LinkPreview{
+		Title:       truncateText(first(meta["og:title"], meta["twitter:title"], strings.TrimSpace(title)), maxPreviewTitle),
+		Description: truncateText(first(meta["og:description"], meta["twitter:description"], meta["description"]), maxPreviewText),
+		ImageURL:    first(meta["og:image:secure_url"], meta["og:image"], meta["twitter:image"], meta["twitter:image:src"]),
+		SiteName:    truncateText(meta["og:site_name"], maxPreviewTitle),
+	}
+}
+
+func truncateText(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:max])) + "…"
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"socialnet/internal/database"
+	"socialnet/internal/model"
+	"socialnet/internal/repository"
+	"socialnet/internal/security"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const previewPNG = "\x89PNG\r\n\x1a\n"
+
+type previewEnv struct {
+	t       *testing.T
+	repo    *repository.LinkPreviewRepository
+	service *LinkPreviewService
+	dir     string
+}
+
+// newPreviewEnv builds a preview service whose client may reach the loopback
+// test server, as it would with LINK_PREVIEW_ALLOW_PRIVATE set.
+func newPreviewEnv(t *testing.T, timeout time.Duration) *previewEnv {
+	t.Helper()
+
+	dir := t.TempDir()
+	db, err := database.New(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := repository.NewLinkPreviewRepository(db.DB)
+	return &previewEnv{
+		t:       t,
+		repo:    repo,
+		service: NewLinkPreviewService(repo, security.NewSafeHTTPClient(timeout, true), dir, make(chan string, 10)),
+		dir:     dir,
+	}
+}
+
+// fetch queues link the way a new post does, runs the worker step and
+// returns the stored row.
+func (e *previewEnv) fetch(link string) *model.LinkPreview {
+	e.t.Helper()
+
+	e.service.Request("have a look at " + link)
+	if err := e.service.Process(<-e.service.queue); err != nil {
+		e.t.Fatal(err)
+	}
+	preview, err := e.repo.GetByURL(link)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	if preview == nil {
+		e.t.Fatalf("no preview stored for %s", link)
+	}
+	return preview
+}
+
+func previewServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	pages := map[string]string{
+		"/og": `<html><head><title>Plain title</title>
+			<meta name="twitter:title" content="Twitter title">
+			<meta property="og:title" content="OG title">
+			<meta property="og:description" content="OG description">
+			<meta property="og:site_name" content="Example">
+			<meta property="og:image" content="/image.png">
+			</head><body></body></html>`,
+		"/twitter": `<html><head><title>Plain title</title>
+			<meta name="twitter:title" content="Twitter title">
+			<meta name="twitter:description" content="Twitter description">
+			</head><body></body></html>`,
+		"/title": `<html><head><title>  Plain
+			title </title><meta name="description" content="Plain description"></head></html>`,
+		"/big-image": `<html><head><title>Big image</title>
+			<meta property="og:image" content="/big.png"></head></html>`,
+		"/big-page": `<html><head><!--` + strings.Repeat("x", 1<<20) + `--><title>Too late</title></head></html>`,
+	}
+
+	mux := http.NewServeMux()
+	for path, page := range pages {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, page)
+		})
+	}
+	mux.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, previewPNG+"small image")
+	})
+	mux.HandleFunc("/big.png", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, previewPNG+strings.Repeat("x", 5<<20))
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLinkPreviewPrefersOpenGraph(t *testing.T) {
+	server := previewServer(t)
+	env := newPreviewEnv(t, 2*time.Second)
+
+	preview := env.fetch(server.URL + "/og")
+	if preview.Status != model.LinkPreviewReady || preview.Title != "OG title" ||
+		preview.Description != "OG description" || preview.SiteName != "Example" {
+		t.Fatalf("got %+v", preview)
+	}
+	if !strings.HasPrefix(preview.ImageURL, "/uploads/preview_") {
+		t.Fatalf("image was not stored locally: %q", preview.ImageURL)
+	}
+	data, err := os.ReadFile(filepath.Join(env.dir, strings.TrimPrefix(preview.ImageURL, "/uploads/")))
+	if err != nil || string(data) != previewPNG+"small image" {
+		t.Fatalf("stored image: %q, %v", data, err)
+	}
+}
+
+func TestLinkPreviewFallsBackToTwitterAndTitle(t *testing.T) {
+	server := previewServer(t)
+	env := newPreviewEnv(t, 2*time.Second)
+
+	preview := env.fetch(server.URL + "/twitter")
+	if preview.Title != "Twitter title" || preview.Description != "Twitter description" {
+		t.Fatalf("twitter card: got %+v", preview)
+	}
+
+	preview = env.fetch(server.URL + "/title")
+	if preview.Title != "Plain title" || preview.Description != "Plain description" {
+		t.Fatalf("title tag: got %+v", preview)
+	}
+}
+
+func TestLinkPreviewLimits(t *testing.T) {
+	server := previewServer(t)
+	env := newPreviewEnv(t, 200*time.Millisecond)
+
+	// Only the first megabyte of a page is read.
+	if preview := env.fetch(server.URL + "/big-page"); preview.Status != model.LinkPreviewFailed {
+		t.Fatalf("oversized page: got %+v", preview)
+	}
+
+	// An oversized image is dropped but the preview is kept.
+	preview := env.fetch(server.URL + "/big-image")
+	if preview.Status != model.LinkPreviewReady || preview.Title != "Big image" || preview.ImageURL != "" {
+		t.Fatalf("oversized image: got %+v", preview)
+	}
+
+	start := time.Now()
+	if preview := env.fetch(server.URL + "/slow"); preview.Status != model.LinkPreviewFailed {
+		t.Fatalf("slow page: got %+v", preview)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("slow page took %v", elapsed)
+	}
+}
+
+func TestSafeHTTPClientRefusesLoopback(t *testing.T) {
+	var reached atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached.Store(true)
+	}))
+	defer server.Close()
+
+	_, err := security.NewSafeHTTPClient(time.Second, false).Get(server.URL)
+	if !errors.Is(err, security.ErrForbiddenDestination) {
+		t.Fatalf("got %v, want %v", err, security.ErrForbiddenDestination)
+	}
+	if reached.Load() {
+		t.Fatal("request reached the loopback server")
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"socialnet/internal/model"
+	"socialnet/internal/repository"
+	"strings"
+)
+
+type MediaService struct {
+	mediaRepo *repository.MediaRepository
+	uploadDir string
+}
+
+func NewMediaService(mediaRepo *repository.MediaRepository, uploadDir string) *MediaService {
+	return &MediaService{
+		mediaRepo: mediaRepo,
+		uploadDir: uploadDir,
+	}
+}
+
+func (s *MediaService) RegisterUpload(userID int64, url, contentType string, size int64) (*model.Media, error) {
+	media := &model.Media{
+		UserID:      userID,
+		URL:         url,
+		ContentType: contentType,
+		Size:        size,
+	}
+
+	id, err := s.mediaRepo.Create(media)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.mediaRepo.GetByID(id)
+}
+
+// ResolveAttachments checks that every referenced media item exists and was
+// uploaded by the poster, and numbers the attachments in the given order.
+func (s *MediaService) ResolveAttachments(userID int64, creates []model.AttachmentCreate) ([]*model.Attachment, error) {
+	if len(creates) > model.MaxAttachments {
+		return nil, errors.New("too many attachments")
+	}
+
+	seen := make(map[int64]bool)
+	attachments := make([]*model.Attachment, 0, len(creates))
+	for i, create := range creates {
+		if seen[create.MediaID] {
+			return nil, errors.New("duplicate attachment")
+		}
+		seen[create.MediaID] = true
+
+		if len(create.AltText) > model.MaxAltTextLength {
+			return nil, errors.New("alt text exceeds maximum length")
+		}
+
+		media, err := s.mediaRepo.GetByID(create.MediaID)
+		if err != nil {
+			return nil, err
+		}
+		if media.UserID != userID {
+			return nil, errors.New("media not found")
+		}
+
+		attachments = append(attachments, &model.Attachment{
+			MediaID:  media.ID,
+			URL:      media.URL,
+			AltText:  create.AltText,
+			Position: i,
+		})
+	}
+
+	return attachments, nil
+}
+
+// ValidateMediaURL keeps the single media_url field limited to the user's own
+// uploads.
+func (s *MediaService) ValidateMediaURL(userID int64, url string) error {
+	if url == "" {
+		return nil
+	}
+
+	media, err := s.mediaRepo.GetByURL(url)
+	if err != nil || media.UserID != userID {
+		return errors.New("media_url must reference your own upload")
+	}
+	return nil
+}
+
+func (s *MediaService) SetAttachments(targetType model.AttachmentTargetType, targetID int64, attachments []*model.Attachment) error {
+	return s.mediaRepo.SetAttachments(targetType, targetID, attachments)
+}
+
+func (s *MediaService) GetAttachments(targetType model.AttachmentTargetType, targetID int64) []*model.Attachment {
+	attachments, _ := s.mediaRepo.GetAttachments(targetType, targetID)
+	return attachments
+}
+
+// DeleteIfUnused removes an upload and its file once nothing references it.
+func (s *MediaService) DeleteIfUnused(url string) error {
+	if !strings.HasPrefix(url, "/uploads/") {
+		return nil
+	}
+
+	referenced, err := s.mediaRepo.IsReferenced(url)
+	if err != nil || referenced {
+		return err
+	}
+
+	if err := s.mediaRepo.DeleteByURL(url); err != nil {
+		return err
+	}
+
+	if s.uploadDir == "" {
+		return nil
+	}
+	err = os.Remove(filepath.Join(s.uploadDir, filepath.Base(url)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
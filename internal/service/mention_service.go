@@ -0,0 +1,122 @@
+package service
+
+import (
+	"log"
+	"regexp"
+	"socialnet/internal/model"
+	"socialnet/internal/repository"
+	"strings"
+)
+
+const maxMentionsPerItem = 20
+
+var mentionRegex = regexp.MustCompile(`(^|[^\p{L}\p{N}_@])@([\p{L}\p{N}_]{3,30})`)
+
+type MentionService struct {
+	mentionRepo  *repository.MentionRepository
+	userRepo     *repository.UserRepository
+	blockService *BlockService
+	notifQueue   chan *model.Notification
+}
+
+func NewMentionService(mentionRepo *repository.MentionRepository, userRepo *repository.UserRepository,
+	blockService *BlockService, notifQueue chan *model.Notification) *MentionService {
+	return &MentionService{
+		mentionRepo:  mentionRepo,
+		userRepo:     userRepo,
+		blockService: blockService,
+		notifQueue:   notifQueue,
+	}
+}
+
+// ParseMentions returns the distinct usernames mentioned in content, in order
+// of first appearance.
+func ParseMentions(content string) []string {
+	seen := make(map[string]bool)
+	var usernames []string
+	for _, match := range mentionRegex.FindAllStringSubmatch(content, -1) {
+		key := strings.ToLower(match[2])
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		usernames = append(usernames, match[2])
+		if len(usernames) == maxMentionsPerItem {
+			break
+		}
+	}
+	return usernames
+}
+
+// ProcessMentions records the mentions found in content and notifies each
+// newly mentioned user. linkID is the notification target the client opens
+// (the post for a comment, the group for a group post). canView, if set,
+// filters out users who could not see the content anyway.
+func (s *MentionService) ProcessMentions(authorID int64, targetType model.MentionTargetType, targetID, linkID int64,
+	content string, canView func(userID int64) bool) {
+	usernames := ParseMentions(content)
+	if len(usernames) == 0 {
+		return
+	}
+
+	author, err := s.userRepo.GetByID(authorID)
+	if err != nil {
+		return
+	}
+
+	for _, username := range usernames {
+		user, err := s.userRepo.GetByUsername(username)
+		if err != nil || user.ID == authorID {
+			continue
+		}
+
+		if s.blockService.IsBlocked(authorID, user.ID) {
+			continue
+		}
+
+		if canView != nil && !canView(user.ID) {
+			continue
+		}
+
+		created, err := s.mentionRepo.Create(&model.Mention{
+			UserID:     user.ID,
+			AuthorID:   authorID,
+			TargetType: targetType,
+			TargetID:   targetID,
+		})
+		if err != nil {
+			log.Printf("Failed to save mention: %v", err)
+			continue
+		}
+		if !created {
+			continue
+		}
+
+		s.notifQueue <- &model.Notification{
+			UserID:   user.ID,
+			Type:     model.NotificationMention,
+			TargetID: linkID,
+			Message:  author.Username + " mentioned you in a " + mentionTargetLabel(targetType),
+			Text:     content,
+			ActorID:  authorID,
+		}
+	}
+}
+
+// RemoveMentions forgets who was mentioned in a target whose content is gone.
+func (s *MentionService) RemoveMentions(targetType model.MentionTargetType, targetID int64) error {
+	return s.mentionRepo.DeleteByTarget(targetType, targetID)
+}
+
+func mentionTargetLabel(targetType model.MentionTargetType) string {
+	switch targetType {
+	case model.MentionTargetComment:
+		return "comment"
+	case model.MentionTargetGroupPost:
+		return "group post"
+	case model.MentionTargetMessage:
+		return "message"
+	default:
+		return "post"
+	}
+}
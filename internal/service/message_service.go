@@ -2,36 +2,61 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"socialnet/internal/model"
+	"socialnet/internal/realtime"
 	"socialnet/internal/repository"
 	"socialnet/internal/security"
+	"sort"
+	"strings"
 )
 
+// maxGroupMembers caps group conversations, counting the creator.
+const maxGroupMembers = 100
+
 type MessageService struct {
-	messageRepo *repository.MessageRepository
-	friendRepo  *repository.FriendshipRepository
-	userRepo    *repository.UserRepository
-	notifQueue  chan *model.Notification
+	messageRepo     *repository.MessageRepository
+	friendRepo      *repository.FriendshipRepository
+	blockService    *BlockService
+	userService     *UserService
+	socialService   *SocialService
+	userRepo        *repository.UserRepository
+	mentionService  *MentionService
+	reactionService *ReactionService
+	hub             *realtime.Hub
+	notifQueue      chan *model.Notification
 }
 
 func NewMessageService(messageRepo *repository.MessageRepository, friendRepo *repository.FriendshipRepository,
-	userRepo *repository.UserRepository, notifQueue chan *model.Notification) *MessageService {
+	blockService *BlockService, userService *UserService, socialService *SocialService,
+	userRepo *repository.UserRepository, mentionService *MentionService,
+	reactionService *ReactionService, hub *realtime.Hub, notifQueue chan *model.Notification) *MessageService {
 	return &MessageService{
-		messageRepo: messageRepo,
-		friendRepo:  friendRepo,
-		userRepo:    userRepo,
-		notifQueue:  notifQueue,
+		messageRepo:     messageRepo,
+		friendRepo:      friendRepo,
+		blockService:    blockService,
+		userService:     userService,
+		socialService:   socialService,
+		userRepo:        userRepo,
+		mentionService:  mentionService,
+		reactionService: reactionService,
+		hub:             hub,
+		notifQueue:      notifQueue,
 	}
 }
 
+// StartConversation opens the conversation between user1ID and user2ID, or
+// returns the existing one. Non-friends can only write to users who allow
+// messages from everyone, and their conversation starts out as a message
+// request for user2ID. Opening a conversation that is waiting in your own
+// requests accepts it.
 func (s *MessageService) StartConversation(user1ID, user2ID int64) (*model.Conversation, error) {
 	if user1ID == user2ID {
 		return nil, errors.New("cannot message yourself")
 	}
 
-	areFriends, _ := s.friendRepo.AreFriends(user1ID, user2ID)
-	if !areFriends {
-		return nil, errors.New("can only message friends")
+	if s.blockService.IsBlocked(user1ID, user2ID) {
+		return nil, errors.New("user not found")
 	}
 
 	conversation, err := s.messageRepo.GetConversationBetween(user1ID, user2ID)
@@ -40,39 +65,392 @@ func (s *MessageService) StartConversation(user1ID, user2ID int64) (*model.Conve
 	}
 
 	if conversation != nil {
+		members, err := s.messageRepo.GetMembers(conversation.ID)
+		if err != nil {
+			return nil, err
+		}
+		if members[user1ID].Status != model.ConversationMemberAccepted {
+			if err := s.messageRepo.SetMemberStatus(conversation.ID, user1ID, model.ConversationMemberAccepted); err != nil {
+				return nil, err
+			}
+		}
 		return conversation, nil
 	}
 
-	convID, err := s.messageRepo.CreateConversation()
+	if ok, err := s.userService.CanMessageUser(user1ID, user2ID); !ok {
+		return nil, err
+	}
+
+	recipientStatus := model.ConversationMemberAccepted
+	if areFriends, _ := s.friendRepo.AreFriends(user1ID, user2ID); !areFriends {
+		recipientStatus = model.ConversationMemberRequest
+	}
+
+	convID, err := s.messageRepo.CreateConversation("", false, user1ID)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.messageRepo.AddMember(convID, user1ID); err != nil {
+	if err := s.messageRepo.AddMember(convID, user1ID, user1ID, model.ConversationRoleMember,
+		model.ConversationMemberAccepted); err != nil {
 		return nil, err
 	}
 
-	if err := s.messageRepo.AddMember(convID, user2ID); err != nil {
+	if err := s.messageRepo.AddMember(convID, user2ID, user1ID, model.ConversationRoleMember, recipientStatus); err != nil {
 		return nil, err
 	}
 
 	return &model.Conversation{ID: convID}, nil
 }
 
+// CreateGroup opens a group conversation with userID as its admin. Each
+// participant must accept messages from userID; those who are not friends
+// with userID get the group as a message request.
+func (s *MessageService) CreateGroup(userID int64, create *model.ConversationCreate) (*model.Conversation, error) {
+	title := strings.TrimSpace(create.Title)
+	if title != "" {
+		if err := security.ValidateContent(title, 100); err != nil {
+			return nil, err
+		}
+	}
+
+	var participantIDs []int64
+	seen := map[int64]bool{userID: true}
+	for _, id := range create.ParticipantIDs {
+		if !seen[id] {
+			seen[id] = true
+			participantIDs = append(participantIDs, id)
+		}
+	}
+	if len(participantIDs) == 0 {
+		return nil, errors.New("group needs at least one other participant")
+	}
+	if len(participantIDs)+1 > maxGroupMembers {
+		return nil, fmt.Errorf("group cannot have more than %d members", maxGroupMembers)
+	}
+
+	statuses := make(map[int64]model.ConversationMemberStatus, len(participantIDs))
+	for _, id := range participantIDs {
+		status, err := s.inviteStatus(userID, id)
+		if err != nil {
+			return nil, err
+		}
+		statuses[id] = status
+	}
+
+	convID, err := s.messageRepo.CreateConversation(title, true, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.messageRepo.AddMember(convID, userID, userID, model.ConversationRoleAdmin,
+		model.ConversationMemberAccepted); err != nil {
+		return nil, err
+	}
+	for _, id := range participantIDs {
+		if err := s.messageRepo.AddMember(convID, id, userID, model.ConversationRoleMember, statuses[id]); err != nil {
+			return nil, err
+		}
+	}
+
+	members, err := s.messageRepo.GetMembers(convID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.systemMessage(convID, userID, members, s.username(userID)+" created the group"); err != nil {
+		return nil, err
+	}
+
+	return s.GetConversation(convID, userID)
+}
+
+// inviteStatus checks that userID may add inviteeID to a group and returns
+// the status the invitee starts with.
+func (s *MessageService) inviteStatus(userID, inviteeID int64) (model.ConversationMemberStatus, error) {
+	if _, err := s.userRepo.GetByID(inviteeID); err != nil {
+		return "", errors.New("user not found")
+	}
+	if ok, err := s.userService.CanMessageUser(userID, inviteeID); !ok {
+		return "", err
+	}
+
+	if areFriends, _ := s.friendRepo.AreFriends(userID, inviteeID); !areFriends {
+		return model.ConversationMemberRequest, nil
+	}
+	return model.ConversationMemberAccepted, nil
+}
+
+// GetConversation returns the conversation with its other participant, or
+// with its members for a group.
+func (s *MessageService) GetConversation(conversationID, userID int64) (*model.Conversation, error) {
+	conversation, members, err := s.checkMember(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.fillMembers(conversation, members, userID); err != nil {
+		return nil, err
+	}
+	conversation.Muted = members[userID].Muted
+
+	conversation.UnreadCount, err = s.messageRepo.GetUnreadCount(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return conversation, nil
+}
+
+// UpdateGroup renames a group conversation.
+func (s *MessageService) UpdateGroup(conversationID, userID int64, update *model.ConversationUpdate) (*model.Conversation, error) {
+	title := strings.TrimSpace(update.Title)
+	if err := security.ValidateContent(title, 100); err != nil {
+		return nil, err
+	}
+
+	_, members, err := s.checkAdmin(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.messageRepo.UpdateTitle(conversationID, title); err != nil {
+		return nil, err
+	}
+	body := fmt.Sprintf("%s renamed the group to \"%s\"", s.username(userID), title)
+	if err := s.systemMessage(conversationID, userID, members, body); err != nil {
+		return nil, err
+	}
+
+	return s.GetConversation(conversationID, userID)
+}
+
+// AddMembers adds users to a group conversation. Users who are already
+// members are skipped.
+func (s *MessageService) AddMembers(conversationID, userID int64, add *model.ConversationMembersAdd) (*model.Conversation, error) {
+	_, members, err := s.checkAdmin(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[int64]model.ConversationMemberStatus)
+	var newIDs []int64
+	for _, id := range add.UserIDs {
+		if _, ok := members[id]; ok {
+			continue
+		}
+		if _, ok := statuses[id]; ok {
+			continue
+		}
+		status, err := s.inviteStatus(userID, id)
+		if err != nil {
+			return nil, err
+		}
+		statuses[id] = status
+		newIDs = append(newIDs, id)
+	}
+	if len(members)+len(newIDs) > maxGroupMembers {
+		return nil, fmt.Errorf("group cannot have more than %d members", maxGroupMembers)
+	}
+
+	for _, id := range newIDs {
+		if err := s.messageRepo.AddMember(conversationID, id, userID, model.ConversationRoleMember, statuses[id]); err != nil {
+			return nil, err
+		}
+		members[id] = &model.ConversationMember{UserID: id, Role: model.ConversationRoleMember, Status: statuses[id]}
+
+		body := s.username(userID) + " added " + s.username(id)
+		if err := s.systemMessage(conversationID, userID, members, body); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetConversation(conversationID, userID)
+}
+
+// RemoveMember removes memberID from a group conversation. Removing
+// yourself is the same as leaving.
+func (s *MessageService) RemoveMember(conversationID, userID, memberID int64) error {
+	if memberID == userID {
+		return s.LeaveGroup(conversationID, userID)
+	}
+
+	_, members, err := s.checkAdmin(conversationID, userID)
+	if err != nil {
+		return err
+	}
+	if _, ok := members[memberID]; !ok {
+		return errors.New("user is not a member of this conversation")
+	}
+
+	if err := s.messageRepo.RemoveMember(conversationID, memberID); err != nil {
+		return err
+	}
+	// The removed member still gets the system message.
+	return s.systemMessage(conversationID, userID, members, s.username(userID)+" removed "+s.username(memberID))
+}
+
+// SetMemberRole promotes a member to admin or demotes an admin. A group
+// always keeps at least one admin.
+func (s *MessageService) SetMemberRole(conversationID, userID, memberID int64, update *model.ConversationRoleUpdate) error {
+	if update.Role != model.ConversationRoleAdmin && update.Role != model.ConversationRoleMember {
+		return errors.New("invalid role")
+	}
+
+	_, members, err := s.checkAdmin(conversationID, userID)
+	if err != nil {
+		return err
+	}
+	member, ok := members[memberID]
+	if !ok {
+		return errors.New("user is not a member of this conversation")
+	}
+	if member.Role == update.Role {
+		return nil
+	}
+	if member.Role == model.ConversationRoleAdmin && countAdmins(members) == 1 {
+		return errors.New("group needs at least one admin")
+	}
+
+	if err := s.messageRepo.SetMemberRole(conversationID, memberID, update.Role); err != nil {
+		return err
+	}
+
+	body := s.username(userID) + " made " + s.username(memberID) + " an admin"
+	if update.Role == model.ConversationRoleMember {
+		body = s.username(userID) + " removed " + s.username(memberID) + " as an admin"
+	}
+	return s.systemMessage(conversationID, userID, members, body)
+}
+
+// LeaveGroup removes userID from a group conversation. When the last admin
+// leaves, the longest-standing member becomes admin.
+func (s *MessageService) LeaveGroup(conversationID, userID int64) error {
+	conversation, members, err := s.checkMember(conversationID, userID)
+	if err != nil {
+		return err
+	}
+	if !conversation.IsGroup {
+		return errors.New("not a group conversation")
+	}
+
+	if err := s.messageRepo.RemoveMember(conversationID, userID); err != nil {
+		return err
+	}
+	if err := s.systemMessage(conversationID, userID, members, s.username(userID)+" left the group"); err != nil {
+		return err
+	}
+
+	wasAdmin := members[userID].Role == model.ConversationRoleAdmin
+	delete(members, userID)
+	if !wasAdmin || len(members) == 0 || countAdmins(members) > 0 {
+		return nil
+	}
+
+	successor := sortedMembers(members)[0]
+	if err := s.messageRepo.SetMemberRole(conversationID, successor.UserID, model.ConversationRoleAdmin); err != nil {
+		return err
+	}
+	successor.Role = model.ConversationRoleAdmin
+	return s.systemMessage(conversationID, successor.UserID, members, s.username(successor.UserID)+" is now an admin")
+}
+
+// systemMessage records a change to the group as a message from actorID
+// and pushes it to members.
+func (s *MessageService) systemMessage(conversationID, actorID int64, members map[int64]*model.ConversationMember,
+	body string) error {
+	blocked, err := s.blockService.BlockedIDs(actorID)
+	if err != nil {
+		return err
+	}
+
+	message := &model.Message{
+		ConversationID: conversationID,
+		UserID:         actorID,
+		Type:           model.MessageSystem,
+		Body:           body,
+	}
+
+	id, err := s.messageRepo.CreateMessage(message)
+	if err != nil {
+		return err
+	}
+	message.ID = id
+	message.Author, _ = s.userRepo.GetByID(actorID)
+
+	s.hub.Publish(audience(members, 0, blocked), &realtime.Event{
+		Type:           realtime.EventMessage,
+		ConversationID: conversationID,
+		UserID:         actorID,
+		Data:           message,
+	})
+	return nil
+}
+
+func (s *MessageService) username(userID int64) string {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return "someone"
+	}
+	return user.Username
+}
+
 func (s *MessageService) SendMessage(conversationID, userID int64, create *model.MessageCreate) (*model.Message, error) {
-	if err := security.ValidateContent(create.Body, 2000); err != nil {
+	return s.sendMessage(conversationID, userID, create.Body, nil)
+}
+
+// SendStoryReply delivers a reply to a story as a direct message to its
+// author, opening the conversation if needed.
+func (s *MessageService) SendStoryReply(userID, authorID, storyID int64, body string) (*model.Message, error) {
+	if err := security.ValidateContent(body, 2000); err != nil {
 		return nil, err
 	}
 
-	isMember, _ := s.messageRepo.IsMember(conversationID, userID)
-	if !isMember {
-		return nil, errors.New("not a member of this conversation")
+	conversation, err := s.StartConversation(userID, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sendMessage(conversation.ID, userID, body, &storyID)
+}
+
+func (s *MessageService) sendMessage(conversationID, userID int64, body string, storyID *int64) (*model.Message, error) {
+	if err := security.ValidateContent(body, 2000); err != nil {
+		return nil, err
+	}
+
+	conversation, members, err := s.checkMember(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.blockService.BlockedIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for memberID, member := range members {
+		switch {
+		case memberID == userID:
+			// Replying to a message request accepts it.
+			if member.Status != model.ConversationMemberAccepted {
+				if err := s.messageRepo.SetMemberStatus(conversationID, userID, model.ConversationMemberAccepted); err != nil {
+					return nil, err
+				}
+				member.Status = model.ConversationMemberAccepted
+			}
+		case !conversation.IsGroup && member.Status != model.ConversationMemberAccepted:
+			// Until they accept, the recipient's privacy setting still applies.
+			// Group members were checked when they were added.
+			if ok, err := s.userService.CanMessageUser(userID, memberID); !ok {
+				return nil, err
+			}
+		}
 	}
 
 	message := &model.Message{
 		ConversationID: conversationID,
 		UserID:         userID,
-		Body:           create.Body,
+		Type:           model.MessageText,
+		Body:           body,
+		StoryID:        storyID,
 	}
 
 	id, err := s.messageRepo.CreateMessage(message)
@@ -82,23 +460,288 @@ func (s *MessageService) SendMessage(conversationID, userID int64, create *model
 
 	message.ID = id
 
+	// Your own messages are never unread.
+	if _, err := s.messageRepo.SetReadCursor(conversationID, userID, id); err != nil {
+		return nil, err
+	}
+
 	sender, _ := s.userRepo.GetByID(userID)
-	notifMessage := sender.Username + " sent you a message"
+	message.Author = sender
 
-	s.notifQueue <- &model.Notification{
-		UserID:   0,
-		Type:     model.NotificationMessage,
-		TargetID: conversationID,
-		Message:  notifMessage,
-	}
+	// The sender gets the event too, for their other devices.
+	s.hub.Publish(audience(members, 0, blocked), &realtime.Event{
+		Type:           realtime.EventMessage,
+		ConversationID: conversationID,
+		UserID:         userID,
+		Data:           message,
+	})
+	s.notifyMembers(conversation, members, message, blocked)
+
+	s.mentionService.ProcessMentions(userID, model.MentionTargetMessage, id, conversationID, message.Body,
+		func(mentionedID int64) bool {
+			isMember, _ := s.messageRepo.IsMember(conversationID, mentionedID)
+			return isMember
+		})
 
 	return message, nil
 }
 
+// notifyMembers queues a notification of the message for each other member
+// who accepted the conversation, except those who muted it, have it open or
+// are in blocked, the users blocked with the sender.
+func (s *MessageService) notifyMembers(conversation *model.Conversation, members map[int64]*model.ConversationMember,
+	message *model.Message, blocked map[int64]bool) {
+	notifMessage := s.username(message.UserID) + " sent you a message"
+	if conversation.IsGroup {
+		notifMessage = s.username(message.UserID) + " sent a message to " + groupName(conversation)
+	}
+
+	for memberID, member := range members {
+		if memberID == message.UserID || blocked[memberID] || member.Muted ||
+			member.Status != model.ConversationMemberAccepted || s.hub.IsViewing(memberID, conversation.ID) {
+			continue
+		}
+
+		s.notifQueue <- &model.Notification{
+			UserID:   memberID,
+			Type:     model.NotificationMessage,
+			TargetID: conversation.ID,
+			Message:  notifMessage,
+			Text:     message.Body,
+			ActorID:  message.UserID,
+		}
+	}
+}
+
+func groupName(conversation *model.Conversation) string {
+	if conversation.Title != "" {
+		return conversation.Title
+	}
+	return "a group"
+}
+
+// MuteConversation turns userID's notifications for the conversation off or
+// back on.
+func (s *MessageService) MuteConversation(conversationID, userID int64, muted bool) error {
+	if _, _, err := s.checkMember(conversationID, userID); err != nil {
+		return err
+	}
+	return s.messageRepo.SetMemberMuted(conversationID, userID, muted)
+}
+
+// CheckAccess reports whether userID can see the conversation.
+func (s *MessageService) CheckAccess(conversationID, userID int64) error {
+	_, _, err := s.checkMember(conversationID, userID)
+	return err
+}
+
+// Typing tells the other members that userID is typing.
+func (s *MessageService) Typing(conversationID, userID int64) error {
+	_, members, err := s.checkMember(conversationID, userID)
+	if err != nil {
+		return err
+	}
+
+	blocked, err := s.blockService.BlockedIDs(userID)
+	if err != nil {
+		return err
+	}
+
+	s.hub.Send(audience(members, userID, blocked), &realtime.Event{
+		Type:           realtime.EventTyping,
+		ConversationID: conversationID,
+		UserID:         userID,
+	})
+	return nil
+}
+
+// MarkRead moves userID's read cursor up to messageID, or to the latest
+// message when messageID is 0. The read receipt goes to the conversation
+// unless userID turned read receipts off or has not accepted the
+// conversation yet; either way their own devices hear about it.
+func (s *MessageService) MarkRead(conversationID, userID, messageID int64) error {
+	conversation, members, err := s.checkMember(conversationID, userID)
+	if err != nil {
+		return err
+	}
+
+	latest, err := s.messageRepo.GetLatestMessageID(conversationID)
+	if err != nil {
+		return err
+	}
+	if messageID <= 0 || messageID > latest {
+		messageID = latest
+	}
+
+	moved, err := s.messageRepo.SetReadCursor(conversationID, userID, messageID)
+	if err != nil || !moved {
+		return err
+	}
+
+	recipients := []int64{userID}
+	reader, err := s.userRepo.GetByID(userID)
+	if err == nil && reader.ReadReceipts && members[userID].Status == model.ConversationMemberAccepted {
+		blocked, err := s.blockService.BlockedIDs(userID)
+		if err != nil {
+			return err
+		}
+		// read_at only means something with a single recipient.
+		if !conversation.IsGroup {
+			if _, err := s.messageRepo.MarkRead(conversationID, userID, messageID); err != nil {
+				return err
+			}
+		}
+		recipients = audience(members, 0, blocked)
+	}
+
+	s.hub.Publish(recipients, &realtime.Event{
+		Type:           realtime.EventRead,
+		ConversationID: conversationID,
+		UserID:         userID,
+		Data:           map[string]int64{"message_id": messageID},
+	})
+	return nil
+}
+
+// GetUnreadSummary returns the unread badge for userID's messages.
+func (s *MessageService) GetUnreadSummary(userID int64) (*model.UnreadSummary, error) {
+	summary, err := s.messageRepo.GetUnreadSummary(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	requests, err := s.GetMessageRequests(userID)
+	if err != nil {
+		return nil, err
+	}
+	summary.Requests = len(requests)
+	return summary, nil
+}
+
+// audience lists the members who should receive live events, leaving out
+// excludeID, anyone in blocked and anyone who ignored the conversation.
+func audience(members map[int64]*model.ConversationMember, excludeID int64, blocked map[int64]bool) []int64 {
+	var userIDs []int64
+	for memberID, member := range members {
+		if memberID != excludeID && !blocked[memberID] && member.Status != model.ConversationMemberIgnored {
+			userIDs = append(userIDs, memberID)
+		}
+	}
+	return userIDs
+}
+
+// checkMember confirms userID belongs to the conversation and returns it
+// with its members. A block between the two people in a direct conversation
+// closes it; in a group it only hides them from each other.
+func (s *MessageService) checkMember(conversationID, userID int64) (*model.Conversation, map[int64]*model.ConversationMember, error) {
+	members, err := s.messageRepo.GetMembers(conversationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := members[userID]; !ok {
+		return nil, nil, errors.New("not a member of this conversation")
+	}
+
+	conversation, err := s.messageRepo.GetConversation(conversationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !conversation.IsGroup {
+		for memberID := range members {
+			if s.blockService.IsBlocked(memberID, userID) {
+				return nil, nil, errors.New("conversation not found")
+			}
+		}
+	}
+	return conversation, members, nil
+}
+
+// checkAdmin is checkMember for changes only group admins may make.
+func (s *MessageService) checkAdmin(conversationID, userID int64) (*model.Conversation, map[int64]*model.ConversationMember, error) {
+	conversation, members, err := s.checkMember(conversationID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !conversation.IsGroup {
+		return nil, nil, errors.New("not a group conversation")
+	}
+	if members[userID].Role != model.ConversationRoleAdmin {
+		return nil, nil, errors.New("only admins can manage the group")
+	}
+	return conversation, members, nil
+}
+
+func countAdmins(members map[int64]*model.ConversationMember) int {
+	admins := 0
+	for _, member := range members {
+		if member.Role == model.ConversationRoleAdmin {
+			admins++
+		}
+	}
+	return admins
+}
+
+// sortedMembers lists members in the order they joined.
+func sortedMembers(members map[int64]*model.ConversationMember) []*model.ConversationMember {
+	list := make([]*model.ConversationMember, 0, len(members))
+	for _, member := range members {
+		list = append(list, member)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].JoinedAt.Equal(list[j].JoinedAt) {
+			return list[i].JoinedAt.Before(list[j].JoinedAt)
+		}
+		return list[i].UserID < list[j].UserID
+	})
+	return list
+}
+
+// fillMembers sets the other participant of a direct conversation, or the
+// members of a group that viewerID can see. Other members never see that
+// someone ignored the group; it still shows as a request. Read cursors are
+// only shown where a read receipt would have been sent.
+func (s *MessageService) fillMembers(conversation *model.Conversation, members map[int64]*model.ConversationMember,
+	viewerID int64) error {
+	if !conversation.IsGroup {
+		for memberID := range members {
+			if memberID != viewerID {
+				conversation.Participant, _ = s.userRepo.GetByID(memberID)
+			}
+		}
+		return nil
+	}
+
+	blocked, err := s.blockService.BlockedIDs(viewerID)
+	if err != nil {
+		return err
+	}
+	conversation.Members = nil
+	for _, member := range sortedMembers(members) {
+		if blocked[member.UserID] {
+			continue
+		}
+		user, err := s.userRepo.GetByID(member.UserID)
+		if err != nil {
+			continue
+		}
+		member.User = user
+		if member.UserID != viewerID && member.Status == model.ConversationMemberIgnored {
+			member.Status = model.ConversationMemberRequest
+		}
+		if member.UserID != viewerID && (!user.ReadReceipts || member.Status != model.ConversationMemberAccepted) {
+			member.LastReadMessageID = 0
+		}
+		conversation.Members = append(conversation.Members, member)
+	}
+	return nil
+}
+
+// GetMessages returns the conversation's messages, leaving out those from
+// group members userID has blocked or been blocked by.
 func (s *MessageService) GetMessages(conversationID, userID int64) ([]*model.Message, error) {
-	isMember, _ := s.messageRepo.IsMember(conversationID, userID)
-	if !isMember {
-		return nil, errors.New("not a member of this conversation")
+	if _, _, err := s.checkMember(conversationID, userID); err != nil {
+		return nil, err
 	}
 
 	messages, err := s.messageRepo.GetMessages(conversationID, 100)
@@ -106,14 +749,115 @@ func (s *MessageService) GetMessages(conversationID, userID int64) ([]*model.Mes
 		return nil, err
 	}
 
+	blocked, err := s.blockService.BlockedIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	visible := messages[:0]
 	for _, message := range messages {
+		if blocked[message.UserID] {
+			continue
+		}
 		author, _ := s.userRepo.GetByID(message.UserID)
 		message.Author = author
+		message.Reactions, _, message.MyReaction = s.reactionService.Summarize(model.ReactionTargetMessage, message.ID, userID)
+		visible = append(visible, message)
 	}
 
-	return messages, nil
+	return visible, nil
 }
 
 func (s *MessageService) GetConversations(userID int64) ([]*model.Conversation, error) {
-	return s.messageRepo.GetUserConversations(userID)
+	return s.listConversations(userID, model.ConversationMemberAccepted)
+}
+
+// GetMessageRequests lists conversations started by non-friends that userID
+// has neither accepted nor ignored.
+func (s *MessageService) GetMessageRequests(userID int64) ([]*model.Conversation, error) {
+	return s.listConversations(userID, model.ConversationMemberRequest)
+}
+
+func (s *MessageService) AcceptRequest(conversationID, userID int64) error {
+	if _, _, err := s.getRequest(conversationID, userID); err != nil {
+		return err
+	}
+	return s.messageRepo.SetMemberStatus(conversationID, userID, model.ConversationMemberAccepted)
+}
+
+// IgnoreRequest hides the request. The sender is not told and can keep
+// writing, but nothing reaches the requests inbox again.
+func (s *MessageService) IgnoreRequest(conversationID, userID int64) error {
+	if _, _, err := s.getRequest(conversationID, userID); err != nil {
+		return err
+	}
+	return s.messageRepo.SetMemberStatus(conversationID, userID, model.ConversationMemberIgnored)
+}
+
+// BlockRequest ignores the request and blocks whoever sent it. For a group
+// that is whoever added userID, not every member.
+func (s *MessageService) BlockRequest(conversationID, userID int64) error {
+	conversation, members, err := s.getRequest(conversationID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.messageRepo.SetMemberStatus(conversationID, userID, model.ConversationMemberIgnored); err != nil {
+		return err
+	}
+
+	if conversation.IsGroup {
+		if addedBy := members[userID].AddedBy; addedBy != 0 && addedBy != userID {
+			return s.socialService.BlockUserByID(userID, addedBy)
+		}
+		return nil
+	}
+
+	for memberID := range members {
+		if memberID == userID {
+			continue
+		}
+		if err := s.socialService.BlockUserByID(userID, memberID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MessageService) getRequest(conversationID, userID int64) (*model.Conversation, map[int64]*model.ConversationMember, error) {
+	conversation, members, err := s.checkMember(conversationID, userID)
+	if err != nil || members[userID].Status == model.ConversationMemberAccepted {
+		return nil, nil, errors.New("message request not found")
+	}
+	return conversation, members, nil
+}
+
+func (s *MessageService) listConversations(userID int64, status model.ConversationMemberStatus) ([]*model.Conversation, error) {
+	conversations, err := s.messageRepo.GetUserConversations(userID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	blocked, err := s.blockService.BlockedIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	visible := conversations[:0]
+	for _, conversation := range conversations {
+		if conversation.IsGroup {
+			members, err := s.messageRepo.GetMembers(conversation.ID)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.fillMembers(conversation, members, userID); err != nil {
+				return nil, err
+			}
+		} else if conversation.Participant != nil && blocked[conversation.Participant.ID] {
+			continue
+		}
+		if conversation.LastMessage != nil && blocked[conversation.LastMessage.UserID] {
+			conversation.LastMessage = nil
+		}
+		visible = append(visible, conversation)
+	}
+	return visible, nil
 }
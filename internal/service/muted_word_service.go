@@ -0,0 +1,136 @@
+package service
+
+import (
+	"errors"
+	"socialnet/internal/model"
+	"socialnet/internal/repository"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type MutedWordService struct {
+	mutedWordRepo *repository.MutedWordRepository
+
+	mu       sync.Mutex
+	matchers map[int64]*wordMatcher
+}
+
+func NewMutedWordService(mutedWordRepo *repository.MutedWordRepository) *MutedWordService {
+	return &MutedWordService{
+		mutedWordRepo: mutedWordRepo,
+		matchers:      make(map[int64]*wordMatcher),
+	}
+}
+
+func (s *MutedWordService) Mute(userID int64, create *model.MutedWordCreate) (*model.MutedWord, error) {
+	phrase, err := normalizeMutedPhrase(create.Phrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if create.ExpiresAt != nil && !create.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expiry must be in the future")
+	}
+
+	count, err := s.mutedWordRepo.CountActive(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= model.MaxMutedWords {
+		return nil, errors.New("too many muted words")
+	}
+
+	word := &model.MutedWord{
+		UserID:    userID,
+		Phrase:    phrase,
+		WholeWord: create.WholeWord == nil || *create.WholeWord,
+		ExpiresAt: create.ExpiresAt,
+	}
+
+	id, err := s.mutedWordRepo.Create(word)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(userID)
+
+	return s.mutedWordRepo.GetByID(id)
+}
+
+func (s *MutedWordService) GetMutedWords(userID int64) ([]*model.MutedWord, error) {
+	return s.mutedWordRepo.GetActive(userID)
+}
+
+func (s *MutedWordService) Unmute(userID, id int64) error {
+	word, err := s.mutedWordRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if word.UserID != userID {
+		return errors.New("muted word not found")
+	}
+
+	if err := s.mutedWordRepo.Delete(id); err != nil {
+		return err
+	}
+	s.invalidate(userID)
+	return nil
+}
+
+// Match returns the user's muted phrases found in any of the texts.
+func (s *MutedWordService) Match(userID int64, texts ...string) []string {
+	return s.matcher(userID).Match(texts...)
+}
+
+// PurgeExpired removes rules whose expiry has passed.
+func (s *MutedWordService) PurgeExpired() error {
+	return s.mutedWordRepo.DeleteExpired()
+}
+
+// matcher returns the user's compiled rules, rebuilding them once one of the
+// rules has expired.
+func (s *MutedWordService) matcher(userID int64) *wordMatcher {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if m, ok := s.matchers[userID]; ok && m.valid(time.Now()) {
+		return m
+	}
+
+	words, err := s.mutedWordRepo.GetActive(userID)
+	if err != nil {
+		return newWordMatcher(nil)
+	}
+
+	m := newWordMatcher(words)
+	s.matchers[userID] = m
+	return m
+}
+
+func (s *MutedWordService) invalidate(userID int64) {
+	s.mu.Lock()
+	delete(s.matchers, userID)
+	s.mu.Unlock()
+}
+
+// normalizeMutedPhrase lowercases a phrase and collapses its whitespace.
+// Hashtag rules must be a single hashtag.
+func normalizeMutedPhrase(phrase string) (string, error) {
+	phrase = strings.ToLower(strings.Join(strings.Fields(phrase), " "))
+	if phrase == "" {
+		return "", errors.New("phrase is required")
+	}
+	if utf8.RuneCountInString(phrase) > model.MaxMutedWordLength {
+		return "", errors.New("phrase is too long")
+	}
+
+	if strings.HasPrefix(phrase, "#") {
+		words, _ := tokenizeWords(phrase)
+		if len(words) != 1 || "#"+words[0] != phrase {
+			return "", errors.New("invalid hashtag")
+		}
+	}
+
+	return phrase, nil
+}
@@ -1,25 +1,72 @@
 package service
 
 import (
+	"fmt"
 	"socialnet/internal/model"
 	"socialnet/internal/repository"
+	"time"
 )
 
+// messageBurstWindow is how long after a sender's last message their next
+// one still joins the same unread notification.
+const messageBurstWindow = 10 * time.Minute
+
 type NotificationService struct {
-	notifRepo *repository.NotificationRepository
+	notifRepo        *repository.NotificationRepository
+	userRepo         *repository.UserRepository
+	mutedWordService *MutedWordService
+	userMuteService  *UserMuteService
 }
 
-func NewNotificationService(notifRepo *repository.NotificationRepository) *NotificationService {
-	return &NotificationService{notifRepo: notifRepo}
+func NewNotificationService(notifRepo *repository.NotificationRepository, userRepo *repository.UserRepository,
+	mutedWordService *MutedWordService, userMuteService *UserMuteService) *NotificationService {
+	return &NotificationService{
+		notifRepo:        notifRepo,
+		userRepo:         userRepo,
+		mutedWordService: mutedWordService,
+		userMuteService:  userMuteService,
+	}
 }
 
+// CreateNotification stores a notification, marking it muted when the content
+// that triggered it matches the recipient's muted words or it was caused by a
+// user the recipient mutes. Muted notifications are kept so they can still be
+// revealed. A burst of messages from one sender in a conversation becomes a
+// single "N new messages" notification while it is unread.
 func (s *NotificationService) CreateNotification(notification *model.Notification) error {
+	if notification.Text != "" && s.mutedWordService.Match(notification.UserID, notification.Text) != nil {
+		notification.Muted = true
+	}
+	if notification.ActorID != 0 && s.userMuteService.IsMuted(notification.UserID, notification.ActorID) {
+		notification.Muted = true
+	}
+
+	if notification.Type == model.NotificationMessage && notification.ActorID != 0 {
+		existing, err := s.notifRepo.FindUnread(notification, time.Now().Add(-messageBurstWindow))
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			count := existing.Count + 1
+			return s.notifRepo.Bump(existing.ID, count, fmt.Sprintf("%d new messages from %s", count,
+				s.username(notification.ActorID)))
+		}
+	}
+
 	_, err := s.notifRepo.Create(notification)
 	return err
 }
 
-func (s *NotificationService) GetNotifications(userID int64) ([]*model.Notification, error) {
-	return s.notifRepo.GetByUser(userID, 50)
+func (s *NotificationService) username(userID int64) string {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return "someone"
+	}
+	return user.Username
+}
+
+func (s *NotificationService) GetNotifications(userID int64, includeMuted bool) ([]*model.Notification, error) {
+	return s.notifRepo.GetByUser(userID, 50, includeMuted)
 }
 
 func (s *NotificationService) MarkAsRead(notificationID int64) error {
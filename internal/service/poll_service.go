@@ -0,0 +1,196 @@
+package service
+
+import (
+	"errors"
+	"socialnet/internal/model"
+	"socialnet/internal/repository"
+	"socialnet/internal/security"
+	"strings"
+	"time"
+)
+
+type PollService struct {
+	pollRepo   *repository.PollRepository
+	postRepo   *repository.PostRepository
+	userRepo   *repository.UserRepository
+	notifQueue chan *model.Notification
+}
+
+func NewPollService(pollRepo *repository.PollRepository, postRepo *repository.PostRepository,
+	userRepo *repository.UserRepository, notifQueue chan *model.Notification) *PollService {
+	return &PollService{
+		pollRepo:   pollRepo,
+		postRepo:   postRepo,
+		userRepo:   userRepo,
+		notifQueue: notifQueue,
+	}
+}
+
+// ValidatePoll checks a poll before its post is created. A poll on a
+// scheduled post must stay open past the publish time.
+func (s *PollService) ValidatePoll(create *model.PollCreate, publishAt *time.Time) error {
+	if len(create.Options) < model.PollMinOptions || len(create.Options) > model.PollMaxOptions {
+		return errors.New("a poll needs between 2 and 10 options")
+	}
+
+	seen := make(map[string]bool)
+	for _, option := range create.Options {
+		if err := security.ValidateContent(option, model.PollOptionMaxLen); err != nil {
+			return err
+		}
+		key := strings.ToLower(strings.TrimSpace(option))
+		if seen[key] {
+			return errors.New("duplicate poll option")
+		}
+		seen[key] = true
+	}
+
+	if create.ClosesAt != nil {
+		opensAt := time.Now()
+		if publishAt != nil {
+			opensAt = *publishAt
+		}
+		if !create.ClosesAt.After(opensAt) {
+			return errors.New("poll must close after it is published")
+		}
+	}
+
+	return nil
+}
+
+func (s *PollService) CreatePoll(postID int64, create *model.PollCreate) error {
+	poll := &model.Poll{
+		PostID:    postID,
+		Multiple:  create.Multiple,
+		Anonymous: create.Anonymous,
+		ClosesAt:  create.ClosesAt,
+	}
+	for i, text := range create.Options {
+		poll.Options = append(poll.Options, &model.PollOption{Position: i, Text: strings.TrimSpace(text)})
+	}
+
+	_, err := s.pollRepo.Create(poll)
+	return err
+}
+
+// GetPoll returns the poll on a post as the viewer should see it, or nil if
+// the post has no poll.
+func (s *PollService) GetPoll(postID, viewerID int64) (*model.Poll, error) {
+	poll, err := s.pollRepo.GetByPostID(postID)
+	if err != nil || poll == nil {
+		return nil, err
+	}
+
+	poll.Closed = poll.ClosesAt != nil && !poll.ClosesAt.After(time.Now())
+	poll.MyVotes, _ = s.pollRepo.GetUserVotes(poll.ID, viewerID)
+	poll.Voted = len(poll.MyVotes) > 0
+
+	if !poll.Voted && !poll.Closed {
+		return poll, nil
+	}
+
+	counts, voters, err := s.pollRepo.GetVoteCounts(poll.ID)
+	if err != nil {
+		return nil, err
+	}
+	poll.VoterCount = &voters
+
+	for _, option := range poll.Options {
+		count := counts[option.ID]
+		option.VoteCount = &count
+
+		if poll.Anonymous || count == 0 {
+			continue
+		}
+		userIDs, _ := s.pollRepo.GetVoterIDs(option.ID)
+		for _, userID := range userIDs {
+			if user, err := s.userRepo.GetByID(userID); err == nil {
+				option.Voters = append(option.Voters, user)
+			}
+		}
+	}
+
+	return poll, nil
+}
+
+func (s *PollService) Vote(postID, userID int64, vote *model.PollVote) (*model.Poll, error) {
+	visible, _ := s.postRepo.IsVisibleTo(postID, userID)
+	if !visible {
+		return nil, errors.New("post not found")
+	}
+
+	poll, err := s.pollRepo.GetByPostID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if poll == nil {
+		return nil, errors.New("post has no poll")
+	}
+
+	if poll.ClosesAt != nil && !poll.ClosesAt.After(time.Now()) {
+		return nil, errors.New("poll is closed")
+	}
+
+	if len(vote.OptionIDs) == 0 {
+		return nil, errors.New("no option selected")
+	}
+	if !poll.Multiple && len(vote.OptionIDs) > 1 {
+		return nil, errors.New("poll allows a single choice")
+	}
+
+	valid := make(map[int64]bool)
+	for _, option := range poll.Options {
+		valid[option.ID] = true
+	}
+	chosen := make(map[int64]bool)
+	for _, optionID := range vote.OptionIDs {
+		if !valid[optionID] {
+			return nil, errors.New("invalid option")
+		}
+		if chosen[optionID] {
+			return nil, errors.New("duplicate option")
+		}
+		chosen[optionID] = true
+	}
+
+	if err := s.pollRepo.Vote(poll.ID, userID, vote.OptionIDs); err != nil {
+		return nil, err
+	}
+
+	return s.GetPoll(postID, userID)
+}
+
+// NotifyClosedPolls tells authors that their polls have closed and returns
+// how many were handled.
+func (s *PollService) NotifyClosedPolls() (int, error) {
+	polls, err := s.pollRepo.GetClosedUnnotified(time.Now(), 100)
+	if err != nil {
+		return 0, err
+	}
+
+	notified := 0
+	for _, poll := range polls {
+		claimed, err := s.pollRepo.MarkCloseNotified(poll.ID)
+		if err != nil {
+			return notified, err
+		}
+		if !claimed {
+			continue
+		}
+
+		post, err := s.postRepo.GetByID(poll.PostID)
+		if err != nil {
+			continue
+		}
+
+		s.notifQueue <- &model.Notification{
+			UserID:   post.UserID,
+			Type:     model.NotificationPollClosed,
+			TargetID: post.ID,
+			Message:  "Your poll has closed, see the results",
+		}
+		notified++
+	}
+
+	return notified, nil
+}
@@ -2,23 +2,51 @@ package service
 
 import (
 	"errors"
+	"socialnet/internal/markdown"
 	"socialnet/internal/model"
 	"socialnet/internal/repository"
 	"socialnet/internal/security"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 type PostService struct {
-	postRepo *repository.PostRepository
-	likeRepo *repository.LikeRepository
-	userRepo *repository.UserRepository
+	postRepo         *repository.PostRepository
+	commentRepo      *repository.CommentRepository
+	userRepo         *repository.UserRepository
+	mentionService   *MentionService
+	reactionService  *ReactionService
+	pollService      *PollService
+	mediaService     *MediaService
+	previewService   *LinkPreviewService
+	bookmarkService  *BookmarkService
+	mutedWordService *MutedWordService
+	blockService     *BlockService
+	userMuteService  *UserMuteService
+	notifQueue       chan *model.Notification
 }
 
-func NewPostService(postRepo *repository.PostRepository, likeRepo *repository.LikeRepository,
-	userRepo *repository.UserRepository) *PostService {
+func NewPostService(postRepo *repository.PostRepository, commentRepo *repository.CommentRepository,
+	userRepo *repository.UserRepository, mentionService *MentionService,
+	reactionService *ReactionService, pollService *PollService, mediaService *MediaService,
+	previewService *LinkPreviewService, bookmarkService *BookmarkService,
+	mutedWordService *MutedWordService, blockService *BlockService, userMuteService *UserMuteService,
+	notifQueue chan *model.Notification) *PostService {
 	return &PostService{
-		postRepo: postRepo,
-		likeRepo: likeRepo,
-		userRepo: userRepo,
+		postRepo:         postRepo,
+		commentRepo:      commentRepo,
+		userRepo:         userRepo,
+		mentionService:   mentionService,
+		reactionService:  reactionService,
+		pollService:      pollService,
+		mediaService:     mediaService,
+		previewService:   previewService,
+		bookmarkService:  bookmarkService,
+		mutedWordService: mutedWordService,
+		blockService:     blockService,
+		userMuteService:  userMuteService,
+		notifQueue:       notifQueue,
 	}
 }
 
@@ -31,6 +59,45 @@ func (s *PostService) CreatePost(userID int64, create *model.PostCreate) (*model
 		UserID:   userID,
 		Content:  create.Content,
 		MediaURL: create.MediaURL,
+		Status:   model.PostStatusPublished,
+	}
+
+	if err := setContentFlags(post, create.ContentFlags); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case create.PublishAt != nil:
+		if !create.PublishAt.After(time.Now()) {
+			return nil, errors.New("publish time must be in the future")
+		}
+		post.Status = model.PostStatusScheduled
+		post.PublishAt = create.PublishAt
+	case create.Draft:
+		post.Status = model.PostStatusDraft
+	}
+
+	if create.QuoteOfID != 0 {
+		original, err := s.getShareable(create.QuoteOfID, userID)
+		if err != nil {
+			return nil, err
+		}
+		post.QuoteOfID = &original.ID
+	}
+
+	if create.Poll != nil {
+		if err := s.pollService.ValidatePoll(create.Poll, post.PublishAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.mediaService.ValidateMediaURL(userID, create.MediaURL); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.mediaService.ResolveAttachments(userID, create.Attachments)
+	if err != nil {
+		return nil, err
 	}
 
 	id, err := s.postRepo.Create(post)
@@ -38,26 +105,345 @@ func (s *PostService) CreatePost(userID int64, create *model.PostCreate) (*model
 		return nil, err
 	}
 
+	if create.Poll != nil {
+		if err := s.pollService.CreatePoll(id, create.Poll); err != nil {
+			s.postRepo.Delete(id)
+			return nil, err
+		}
+	}
+
+	if len(attachments) > 0 {
+		if err := s.mediaService.SetAttachments(model.AttachmentTargetPost, id, attachments); err != nil {
+			s.postRepo.Delete(id)
+			return nil, err
+		}
+	}
+
 	post.ID = id
+	if post.Status == model.PostStatusPublished {
+		s.announcePost(post)
+	}
+
 	return s.GetPost(id, userID)
 }
 
+// announcePost sends the notifications a post triggers once it goes live and
+// queues its link preview. Drafts and scheduled posts stay silent until they
+// are published.
+func (s *PostService) announcePost(post *model.Post) {
+	s.previewService.Request(post.Content)
+	s.mentionService.ProcessMentions(post.UserID, model.MentionTargetPost, post.ID, post.ID, post.Content,
+		postVisibility(s.postRepo, post.ID))
+
+	if post.QuoteOfID != nil {
+		if visible, _ := s.postRepo.IsVisibleTo(*post.QuoteOfID, post.UserID); visible {
+			if quoted, err := s.postRepo.GetByID(*post.QuoteOfID); err == nil {
+				s.notifyShare(quoted, post.UserID, post.ID, " quoted your post", post.Content)
+			}
+		}
+	}
+}
+
+func (s *PostService) GetScheduledPosts(userID int64) ([]*model.Post, error) {
+	posts, err := s.postRepo.GetUnpublished(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, post := range posts {
+		s.hydratePost(post, userID, true)
+	}
+
+	return posts, nil
+}
+
+// getUnpublished loads one of the user's drafts or scheduled posts.
+func (s *PostService) getUnpublished(postID, userID int64) (*model.Post, error) {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.UserID != userID {
+		return nil, errors.New("post not found")
+	}
+
+	if post.Status == model.PostStatusPublished {
+		return nil, errors.New("post is already published")
+	}
+
+	return post, nil
+}
+
+func (s *PostService) VoteInPoll(postID, userID int64, vote *model.PollVote) (*model.Post, error) {
+	if _, err := s.pollService.Vote(postID, userID, vote); err != nil {
+		return nil, err
+	}
+	return s.GetPost(postID, userID)
+}
+
+func (s *PostService) UpdateScheduledPost(postID, userID int64, update *model.PostSchedule) (*model.Post, error) {
+	if err := security.ValidateContent(update.Content, 5000); err != nil {
+		return nil, err
+	}
+
+	post, err := s.getUnpublished(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := model.PostStatusDraft
+	if update.PublishAt != nil {
+		if !update.PublishAt.After(time.Now()) {
+			return nil, errors.New("publish time must be in the future")
+		}
+		status = model.PostStatusScheduled
+
+		poll, _ := s.pollService.GetPoll(postID, userID)
+		if poll != nil && poll.ClosesAt != nil && !poll.ClosesAt.After(*update.PublishAt) {
+			return nil, errors.New("poll must close after it is published")
+		}
+	}
+
+	if update.MediaURL != post.MediaURL {
+		if err := s.mediaService.ValidateMediaURL(userID, update.MediaURL); err != nil {
+			return nil, err
+		}
+	}
+
+	var attachments []*model.Attachment
+	if update.Attachments != nil {
+		if attachments, err = s.mediaService.ResolveAttachments(userID, update.Attachments); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := setContentFlags(post, update.ContentFlags); err != nil {
+		return nil, err
+	}
+
+	post.Content = update.Content
+	post.MediaURL = update.MediaURL
+	if err := s.postRepo.Update(post); err != nil {
+		return nil, err
+	}
+
+	if update.Attachments != nil {
+		if err := s.mediaService.SetAttachments(model.AttachmentTargetPost, postID, attachments); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.postRepo.SetSchedule(postID, status, update.PublishAt); err != nil {
+		return nil, err
+	}
+
+	return s.GetPost(postID, userID)
+}
+
+// CancelScheduledPost turns a scheduled post back into a draft.
+func (s *PostService) CancelScheduledPost(postID, userID int64) error {
+	if _, err := s.getUnpublished(postID, userID); err != nil {
+		return err
+	}
+	return s.postRepo.SetSchedule(postID, model.PostStatusDraft, nil)
+}
+
+// PublishPost publishes a draft or scheduled post right away.
+func (s *PostService) PublishPost(postID, userID int64) (*model.Post, error) {
+	post, err := s.getUnpublished(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.publish(post); err != nil {
+		return nil, err
+	}
+
+	return s.GetPost(postID, userID)
+}
+
+// PublishDuePosts publishes every scheduled post whose time has come and
+// returns how many it published. The schedule lives in the database, so posts
+// that fell due while the server was down go out on the next run.
+func (s *PostService) PublishDuePosts() (int, error) {
+	published := 0
+	for {
+		posts, err := s.postRepo.GetDueScheduled(time.Now(), 100)
+		if err != nil {
+			return published, err
+		}
+
+		for _, post := range posts {
+			if err := s.publish(post); err != nil {
+				return published, err
+			}
+			published++
+		}
+
+		if len(posts) < 100 {
+			return published, nil
+		}
+	}
+}
+
+func (s *PostService) publish(post *model.Post) error {
+	ok, err := s.postRepo.Publish(post.ID)
+	if err != nil {
+		return err
+	}
+	if ok {
+		s.announcePost(post)
+	}
+	return nil
+}
+
+func (s *PostService) Repost(postID, userID int64) (*model.Post, error) {
+	original, err := s.getShareable(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reposted, _ := s.postRepo.HasUserReposted(original.ID, userID)
+	if reposted {
+		return nil, errors.New("already reposted")
+	}
+
+	repost := &model.Post{
+		UserID:     userID,
+		RepostOfID: &original.ID,
+	}
+
+	id, err := s.postRepo.Create(repost)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyShare(original, userID, original.ID, " reposted your post", "")
+
+	return s.GetPost(id, userID)
+}
+
+func (s *PostService) Unrepost(postID, userID int64) error {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return err
+	}
+
+	if post.RepostOfID != nil {
+		postID = *post.RepostOfID
+	}
+
+	return s.postRepo.DeleteRepost(postID, userID)
+}
+
+// getShareable resolves postID to the original post (reposting a repost
+// shares what it points to) and checks the user can see it.
+func (s *PostService) getShareable(postID, userID int64) (*model.Post, error) {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.RepostOfID != nil {
+		post, err = s.postRepo.GetByID(*post.RepostOfID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	visible, _ := s.postRepo.IsVisibleTo(post.ID, userID)
+	if !visible {
+		return nil, errors.New("post not found")
+	}
+
+	return post, nil
+}
+
+func (s *PostService) notifyShare(original *model.Post, userID, targetID int64, action, text string) {
+	if original.UserID == userID {
+		return
+	}
+
+	sharer, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return
+	}
+
+	s.notifQueue <- &model.Notification{
+		UserID:   original.UserID,
+		Type:     model.NotificationRepost,
+		TargetID: targetID,
+		Message:  sharer.Username + action,
+		Text:     text,
+		ActorID:  userID,
+	}
+}
+
 func (s *PostService) GetPost(postID, currentUserID int64) (*model.Post, error) {
 	post, err := s.postRepo.GetByID(postID)
 	if err != nil {
 		return nil, err
 	}
 
+	if post.UserID != currentUserID {
+		if visible, _ := s.postRepo.IsVisibleTo(postID, currentUserID); !visible {
+			return nil, errors.New("post not found")
+		}
+	}
+
+	s.hydratePost(post, currentUserID, true)
+
+	return post, nil
+}
+
+// hydratePost fills in the author, counters and viewer flags. With embed set
+// it also attaches the original of a repost or quote, provided the viewer can
+// still see it.
+func (s *PostService) hydratePost(post *model.Post, viewerID int64, embed bool) {
 	author, _ := s.userRepo.GetByID(post.UserID)
 	post.Author = author
+	post.ContentHTML = markdown.Render(post.Content)
 
-	count, _ := s.likeRepo.GetCountByPostID(postID)
-	post.LikeCount = count
+	post.Reactions, post.LikeCount, post.MyReaction = s.reactionService.Summarize(model.ReactionTargetPost, post.ID, viewerID)
+	post.Liked = post.MyReaction != ""
 
-	liked, _ := s.likeRepo.HasUserLiked(postID, currentUserID)
-	post.Liked = liked
+	post.CommentCount, _ = s.commentRepo.GetCountByPostID(post.ID)
+	post.RepostCount, post.QuoteCount, _ = s.postRepo.GetRepostCounts(post.ID)
+	post.Reposted, _ = s.postRepo.HasUserReposted(post.ID, viewerID)
+	post.Bookmarked = s.bookmarkService.IsBookmarked(viewerID, post.ID)
+	post.Collapsed = (post.ContentWarning != "" || post.Sensitive) && post.UserID != viewerID &&
+		hidesSensitive(s.userRepo, viewerID)
+	post.Poll, _ = s.pollService.GetPoll(post.ID, viewerID)
+	post.Attachments = s.mediaService.GetAttachments(model.AttachmentTargetPost, post.ID)
+	post.LinkPreview = s.previewService.GetPreview(post.Content)
 
-	return post, nil
+	if !embed {
+		return
+	}
+
+	if post.RepostOfID != nil {
+		post.RepostOf = s.getEmbedded(*post.RepostOfID, viewerID)
+	}
+	if post.QuoteOfID != nil {
+		post.QuotedPost = s.getEmbedded(*post.QuoteOfID, viewerID)
+		post.QuoteUnavailable = post.QuotedPost == nil
+	}
+}
+
+func (s *PostService) getEmbedded(postID, viewerID int64) *model.Post {
+	visible, _ := s.postRepo.IsVisibleTo(postID, viewerID)
+	if !visible {
+		return nil
+	}
+
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return nil
+	}
+
+	s.hydratePost(post, viewerID, false)
+	return post
 }
 
 func (s *PostService) UpdatePost(postID, userID int64, update *model.PostUpdate) error {
@@ -74,10 +460,68 @@ func (s *PostService) UpdatePost(postID, userID int64, update *model.PostUpdate)
 		return errors.New("unauthorized")
 	}
 
+	if post.RepostOfID != nil {
+		return errors.New("cannot edit a repost")
+	}
+
+	if update.MediaURL != post.MediaURL {
+		if err := s.mediaService.ValidateMediaURL(userID, update.MediaURL); err != nil {
+			return err
+		}
+	}
+
+	var attachments []*model.Attachment
+	if update.Attachments != nil {
+		if attachments, err = s.mediaService.ResolveAttachments(userID, update.Attachments); err != nil {
+			return err
+		}
+	}
+
+	if err := setContentFlags(post, update.ContentFlags); err != nil {
+		return err
+	}
+
 	post.Content = update.Content
 	post.MediaURL = update.MediaURL
 
-	return s.postRepo.Update(post)
+	if err := s.postRepo.Update(post); err != nil {
+		return err
+	}
+
+	if update.Attachments != nil {
+		if err := s.mediaService.SetAttachments(model.AttachmentTargetPost, postID, attachments); err != nil {
+			return err
+		}
+	}
+
+	if post.Status == model.PostStatusPublished {
+		s.previewService.Request(post.Content)
+		s.mentionService.ProcessMentions(userID, model.MentionTargetPost, postID, postID, post.Content,
+			postVisibility(s.postRepo, postID))
+	}
+	return nil
+}
+
+// postVisibility reports whether a user can see postID, for leaving out
+// mentions of users outside the post's audience.
+func postVisibility(postRepo *repository.PostRepository, postID int64) func(userID int64) bool {
+	return func(userID int64) bool {
+		visible, _ := postRepo.IsVisibleTo(postID, userID)
+		return visible
+	}
+}
+
+func (s *PostService) GetRevisions(postID, userID int64, isAdmin bool) ([]*model.PostRevision, error) {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.UserID != userID && !isAdmin {
+		return nil, errors.New("unauthorized")
+	}
+
+	return s.postRepo.GetRevisions(postID)
 }
 
 func (s *PostService) DeletePost(postID, userID int64, isAdmin bool) error {
@@ -93,22 +537,149 @@ func (s *PostService) DeletePost(postID, userID int64, isAdmin bool) error {
 	return s.postRepo.Delete(postID)
 }
 
-func (s *PostService) GetFeed(userID int64) ([]*model.Post, error) {
+// GetFeed returns the user's feed. Posts and reposts by muted users are always
+// left out. Posts matching muted words are left out, or kept and tagged with
+// the matched words when includeMuted is set.
+func (s *PostService) GetFeed(userID int64, includeMuted bool) ([]*model.Post, error) {
 	posts, err := s.postRepo.GetFeed(userID, 50)
 	if err != nil {
 		return nil, err
 	}
 
+	// Muted users are dropped before reposts are collapsed, so their reposts
+	// neither stand in for an original nor show up in RepostedBy.
+	mutedUsers := s.userMuteService.MutedIDs(userID)
+	unmuted := posts[:0]
 	for _, post := range posts {
-		author, _ := s.userRepo.GetByID(post.UserID)
-		post.Author = author
+		if mutedUsers[post.UserID] {
+			continue
+		}
+		s.hydratePost(post, userID, true)
+		if post.RepostOf != nil && mutedUsers[post.RepostOf.UserID] {
+			continue
+		}
+		unmuted = append(unmuted, post)
+	}
+
+	feed := dedupeFeed(unmuted)
+
+	visible := feed[:0]
+	for _, post := range feed {
+		if post.UserID != userID {
+			post.MutedWords = s.mutedWordService.Match(userID, postMuteTexts(post)...)
+		}
+		if post.MutedWords == nil || includeMuted {
+			visible = append(visible, post)
+		}
+	}
+
+	return visible, nil
+}
+
+// postMuteTexts collects the text of a post that muted words apply to,
+// including a reposted or quoted post and poll options.
+func postMuteTexts(post *model.Post) []string {
+	texts := []string{post.Content, post.ContentWarning}
+	if post.Poll != nil {
+		for _, option := range post.Poll.Options {
+			texts = append(texts, option.Text)
+		}
+	}
+	if post.RepostOf != nil {
+		texts = append(texts, postMuteTexts(post.RepostOf)...)
+	}
+	if post.QuotedPost != nil {
+		texts = append(texts, postMuteTexts(post.QuotedPost)...)
+	}
+	return texts
+}
 
-		count, _ := s.likeRepo.GetCountByPostID(post.ID)
-		post.LikeCount = count
+func (s *PostService) GetBookmarks(userID int64, collectionID *int64, limit, offset int) ([]*model.Post, error) {
+	ids, err := s.bookmarkService.GetBookmarkedPostIDs(userID, collectionID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
 
-		liked, _ := s.likeRepo.HasUserLiked(post.ID, userID)
-		post.Liked = liked
+	posts := make([]*model.Post, 0, len(ids))
+	for _, id := range ids {
+		post, err := s.postRepo.GetByID(id)
+		if err != nil {
+			continue
+		}
+		s.hydratePost(post, userID, true)
+		posts = append(posts, post)
 	}
 
 	return posts, nil
 }
+
+// dedupeFeed collapses a post and its reposts into a single entry at the
+// position of the newest one, collecting who reposted it. Plain reposts whose
+// original the viewer can no longer see are dropped.
+func dedupeFeed(posts []*model.Post) []*model.Post {
+	seen := make(map[int64]*model.Post)
+	result := make([]*model.Post, 0, len(posts))
+
+	for _, post := range posts {
+		key := post.ID
+		if post.RepostOfID != nil {
+			if post.RepostOf == nil {
+				continue
+			}
+			key = *post.RepostOfID
+		}
+
+		if first, ok := seen[key]; ok {
+			if post.RepostOfID != nil && post.Author != nil {
+				first.RepostedBy = append(first.RepostedBy, post.Author)
+			}
+			continue
+		}
+
+		if post.RepostOfID != nil && post.Author != nil {
+			post.RepostedBy = []*model.User{post.Author}
+		}
+		seen[key] = post
+		result = append(result, post)
+	}
+
+	return result
+}
+
+// applyContentFlags merges flag changes into the current content warning and
+// sensitive flag.
+func applyContentFlags(flags model.ContentFlags, contentWarning string, sensitive bool) (string, bool, error) {
+	if flags.ContentWarning != nil {
+		contentWarning = strings.TrimSpace(*flags.ContentWarning)
+		if utf8.RuneCountInString(contentWarning) > model.MaxContentWarningLength {
+			return "", false, errors.New("content warning exceeds maximum length")
+		}
+	}
+	if flags.Sensitive != nil {
+		sensitive = *flags.Sensitive
+	}
+	return contentWarning, sensitive, nil
+}
+
+// setContentFlags applies the author's flag changes. Flags a moderator set
+// cannot be changed by the author.
+func setContentFlags(post *model.Post, flags model.ContentFlags) error {
+	contentWarning, sensitive, err := applyContentFlags(flags, post.ContentWarning, post.Sensitive)
+	if err != nil {
+		return err
+	}
+
+	if post.FlagsLocked && (contentWarning != post.ContentWarning || sensitive != post.Sensitive) {
+		return errors.New("content flags were set by a moderator")
+	}
+
+	post.ContentWarning = contentWarning
+	post.Sensitive = sensitive
+	return nil
+}
+
+// hidesSensitive reports whether the viewer wants flagged content collapsed.
+func hidesSensitive(userRepo *repository.UserRepository, viewerID int64) bool {
+	prefs, err := userRepo.GetPreferences(viewerID)
+	return err != nil || prefs.SensitiveContent != model.SensitiveContentExpand
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"errors"
+	"socialnet/internal/model"
+	"socialnet/internal/repository"
+)
+
+type ReactionService struct {
+	reactionRepo *repository.ReactionRepository
+	postRepo     *repository.PostRepository
+	commentRepo  *repository.CommentRepository
+	messageRepo  *repository.MessageRepository
+	userRepo     *repository.UserRepository
+	blockService *BlockService
+	available    []model.ReactionType
+	notifQueue   chan *model.Notification
+}
+
+// NewReactionService restricts reactions to the enabled IDs from the
+// catalogue. An empty list enables the whole catalogue.
+func NewReactionService(reactionRepo *repository.ReactionRepository, postRepo *repository.PostRepository,
+	commentRepo *repository.CommentRepository, messageRepo *repository.MessageRepository,
+	userRepo *repository.UserRepository, blockService *BlockService, enabled []string,
+	notifQueue chan *model.Notification) *ReactionService {
+	available := model.PredefinedReactions
+	if len(enabled) > 0 {
+		available = nil
+		for _, id := range enabled {
+			if r := model.GetReactionByID(id); r != nil {
+				available = append(available, *r)
+			}
+		}
+	}
+
+	return &ReactionService{
+		reactionRepo: reactionRepo,
+		postRepo:     postRepo,
+		commentRepo:  commentRepo,
+		messageRepo:  messageRepo,
+		userRepo:     userRepo,
+		blockService: blockService,
+		available:    available,
+		notifQueue:   notifQueue,
+	}
+}
+
+func (s *ReactionService) GetAvailableReactions() []model.ReactionType {
+	return s.available
+}
+
+func (s *ReactionService) getAvailable(id string) *model.ReactionType {
+	for _, r := range s.available {
+		if r.ID == id {
+			return &r
+		}
+	}
+	return nil
+}
+
+// React sets the user's reaction on a target, replacing a previous one. Only
+// a first reaction notifies the owner; switching reactions does not.
+func (s *ReactionService) React(targetType model.ReactionTargetType, targetID, userID int64, reaction string) error {
+	reactionType := s.getAvailable(reaction)
+	if reactionType == nil {
+		return errors.New("invalid reaction")
+	}
+
+	ownerID, linkID, err := s.checkAccess(targetType, targetID, userID)
+	if err != nil {
+		return err
+	}
+
+	previous, err := s.reactionRepo.GetUserReaction(targetType, targetID, userID)
+	if err != nil {
+		return err
+	}
+	if previous == reaction {
+		return nil
+	}
+
+	if err := s.reactionRepo.Set(&model.Reaction{
+		TargetType: targetType,
+		TargetID:   targetID,
+		UserID:     userID,
+		Reaction:   reaction,
+	}); err != nil {
+		return err
+	}
+
+	if previous == "" && ownerID != userID && targetType != model.ReactionTargetMessage {
+		reactor, _ := s.userRepo.GetByID(userID)
+		if reactor != nil {
+			s.notifQueue <- &model.Notification{
+				UserID:   ownerID,
+				Type:     model.NotificationReaction,
+				TargetID: linkID,
+				Message:  reactor.Username + " reacted " + reactionType.Char + " to your " + string(targetType),
+				ActorID:  userID,
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *ReactionService) RemoveReaction(targetType model.ReactionTargetType, targetID, userID int64) error {
+	if _, _, err := s.checkAccess(targetType, targetID, userID); err != nil {
+		return err
+	}
+	return s.reactionRepo.Delete(targetType, targetID, userID)
+}
+
+// GetReactions lists who reacted to a target, optionally narrowed to one
+// reaction.
+func (s *ReactionService) GetReactions(targetType model.ReactionTargetType, targetID, viewerID int64,
+	reaction string, limit, offset int) ([]*model.Reaction, error) {
+	if reaction != "" && s.getAvailable(reaction) == nil {
+		return nil, errors.New("invalid reaction")
+	}
+
+	if _, _, err := s.checkAccess(targetType, targetID, viewerID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	reactions, err := s.reactionRepo.GetByTarget(targetType, targetID, reaction, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	blocked, err := s.blockService.BlockedIDs(viewerID)
+	if err != nil {
+		return nil, err
+	}
+	visible := reactions[:0]
+	for _, r := range reactions {
+		if blocked[r.UserID] {
+			continue
+		}
+		user, _ := s.userRepo.GetByID(r.UserID)
+		r.User = user
+		visible = append(visible, r)
+	}
+
+	return visible, nil
+}
+
+// Summarize returns the per-reaction counts on a target, their total and the
+// viewer's own reaction.
+func (s *ReactionService) Summarize(targetType model.ReactionTargetType, targetID, viewerID int64) (map[string]int, int, string) {
+	counts, err := s.reactionRepo.GetCounts(targetType, targetID)
+	if err != nil {
+		counts = map[string]int{}
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	mine, _ := s.reactionRepo.GetUserReaction(targetType, targetID, viewerID)
+	return counts, total, mine
+}
+
+// checkAccess confirms the target exists and the user may react to it. It
+// returns the target's owner and the ID notifications should link to.
+func (s *ReactionService) checkAccess(targetType model.ReactionTargetType, targetID, userID int64) (int64, int64, error) {
+	ownerID, linkID, err := s.findTarget(targetType, targetID, userID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if s.blockService.IsBlocked(ownerID, userID) {
+		return 0, 0, errors.New(string(targetType) + " not found")
+	}
+	return ownerID, linkID, nil
+}
+
+func (s *ReactionService) findTarget(targetType model.ReactionTargetType, targetID, userID int64) (int64, int64, error) {
+	switch targetType {
+	case model.ReactionTargetPost:
+		post, err := s.postRepo.GetByID(targetID)
+		if err != nil {
+			return 0, 0, err
+		}
+		if visible, _ := s.postRepo.IsVisibleTo(post.ID, userID); !visible {
+			return 0, 0, errors.New("post not found")
+		}
+		return post.UserID, post.ID, nil
+	case model.ReactionTargetComment:
+		comment, err := s.commentRepo.GetByID(targetID)
+		if err != nil {
+			return 0, 0, err
+		}
+		if comment.Deleted {
+			return 0, 0, errors.New("comment not found")
+		}
+		if visible, _ := s.postRepo.IsVisibleTo(comment.PostID, userID); !visible {
+			return 0, 0, errors.New("comment not found")
+		}
+		return comment.UserID, comment.PostID, nil
+	case model.ReactionTargetMessage:
+		message, err := s.messageRepo.GetMessageByID(targetID)
+		if err != nil {
+			return 0, 0, err
+		}
+		isMember, _ := s.messageRepo.IsMember(message.ConversationID, userID)
+		if !isMember {
+			return 0, 0, errors.New("message not found")
+		}
+		return message.UserID, message.ConversationID, nil
+	}
+	return 0, 0, errors.New("invalid reaction target")
+}
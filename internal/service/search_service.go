@@ -0,0 +1,171 @@
+package service
+
+import (
+	"errors"
+	"html"
+	"socialnet/internal/markdown"
+	"socialnet/internal/model"
+	"socialnet/internal/repository"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+)
+
+type SearchService struct {
+	searchRepo    *repository.SearchRepository
+	postService   *PostService
+	socialService *SocialService
+	userRepo      *repository.UserRepository
+	groupRepo     *repository.GroupRepository
+}
+
+func NewSearchService(searchRepo *repository.SearchRepository, postService *PostService, socialService *SocialService,
+	userRepo *repository.UserRepository, groupRepo *repository.GroupRepository) *SearchService {
+	return &SearchService{
+		searchRepo:    searchRepo,
+		postService:   postService,
+		socialService: socialService,
+		userRepo:      userRepo,
+		groupRepo:     groupRepo,
+	}
+}
+
+func (s *SearchService) Search(viewerID int64, filter *model.SearchFilter) ([]*model.SearchResult, error) {
+	if repository.FTSMatch(filter.Query) == "" {
+		return nil, errors.New("search query required")
+	}
+	if filter.Type != "" && !isSearchType(filter.Type) {
+		return nil, errors.New("invalid search type")
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultSearchLimit
+	}
+	if filter.Limit > maxSearchLimit {
+		filter.Limit = maxSearchLimit
+	}
+
+	var results []*model.SearchResult
+	add := func(found []*model.SearchResult, err error) error {
+		if err != nil {
+			return err
+		}
+		normalizeRanks(found)
+		results = append(results, found...)
+		return nil
+	}
+
+	all := filter.Type == ""
+	if all || filter.Type == model.SearchTypePost {
+		if err := add(s.searchRepo.SearchPosts(viewerID, filter)); err != nil {
+			return nil, err
+		}
+	}
+	if all || filter.Type == model.SearchTypeComment {
+		if err := add(s.searchRepo.SearchComments(viewerID, filter)); err != nil {
+			return nil, err
+		}
+	}
+	if all || filter.Type == model.SearchTypeGroupPost {
+		if err := add(s.searchRepo.SearchGroupPosts(viewerID, filter)); err != nil {
+			return nil, err
+		}
+	}
+	if all || filter.Type == model.SearchTypeGroup {
+		if err := add(s.searchRepo.SearchGroups(filter)); err != nil {
+			return nil, err
+		}
+	}
+	// Users have no author, so an author filter rules them out of mixed results.
+	if (all && filter.AuthorID == 0) || filter.Type == model.SearchTypeUser {
+		if err := add(s.searchRepo.SearchUsers(viewerID, filter)); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].CreatedAt.After(results[j].CreatedAt)
+	})
+	if len(results) > filter.Limit {
+		results = results[:filter.Limit]
+	}
+
+	hydrated := results[:0]
+	for _, result := range results {
+		result.Snippet = highlightSnippet(result.Snippet)
+		if s.hydrateResult(result, viewerID) {
+			hydrated = append(hydrated, result)
+		}
+	}
+
+	return hydrated, nil
+}
+
+// normalizeRanks rescales the bm25 scores of one result type against its best
+// match, which scores 1. Raw bm25 depends on each FTS table's own term
+// statistics, so scores from different tables are not comparable.
+func normalizeRanks(results []*model.SearchResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	// bm25 scores are negative and the repository returns the best one first.
+	best := results[0].Rank
+	for _, result := range results {
+		if best < 0 {
+			result.Rank /= best
+		} else {
+			result.Rank = 1
+		}
+	}
+}
+
+// hydrateResult fills in a result the same way its own endpoint would. It
+// reports false if the viewer can no longer see the result.
+func (s *SearchService) hydrateResult(result *model.SearchResult, viewerID int64) bool {
+	switch result.Type {
+	case model.SearchTypePost:
+		post, err := s.postService.GetPost(result.ID, viewerID)
+		if err != nil {
+			return false
+		}
+		result.Post = post
+	case model.SearchTypeComment:
+		comment, err := s.socialService.GetComment(result.ID, viewerID)
+		if err != nil {
+			return false
+		}
+		result.Comment = comment
+	case model.SearchTypeGroupPost:
+		result.GroupPost.Author, _ = s.userRepo.GetByID(result.GroupPost.UserID)
+		result.GroupPost.ContentHTML = markdown.Render(result.GroupPost.Content)
+	case model.SearchTypeGroup:
+		result.Group.Owner, _ = s.userRepo.GetByID(result.Group.OwnerID)
+		result.Group.MemberCount, _ = s.groupRepo.GetMemberCount(result.Group.ID)
+		result.Group.IsMember, _ = s.groupRepo.IsMember(result.Group.ID, viewerID)
+	}
+	return true
+}
+
+func isSearchType(t model.SearchResultType) bool {
+	switch t {
+	case model.SearchTypePost, model.SearchTypeComment, model.SearchTypeGroupPost,
+		model.SearchTypeGroup, model.SearchTypeUser:
+		return true
+	}
+	return false
+}
+
+// highlightSnippet escapes the snippet text and wraps matched terms in <mark>.
+func highlightSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, repository.SnippetMatchStart, "<mark>")
+	return strings.ReplaceAll(escaped, repository.SnippetMatchEnd, "</mark>")
+}
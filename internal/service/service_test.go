@@ -0,0 +1,163 @@
+package service
+
+import (
+	"database/sql"
+	"path/filepath"
+	"socialnet/internal/database"
+	"socialnet/internal/model"
+	"socialnet/internal/realtime"
+	"socialnet/internal/repository"
+	"socialnet/internal/security"
+	"testing"
+	"time"
+)
+
+// testEnv wires the services the way main does, over a fresh database.
+type testEnv struct {
+	t          *testing.T
+	db         *sql.DB
+	userRepo   *repository.UserRepository
+	friendRepo *repository.FriendshipRepository
+	blocks     *BlockService
+	users      *UserService
+	posts      *PostService
+	social     *SocialService
+	reactions  *ReactionService
+	messages   *MessageService
+	groups     *GroupService
+	search     *SearchService
+	notifQueue chan *model.Notification
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	dir := t.TempDir()
+	db, err := database.New(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	userRepo := repository.NewUserRepository(db.DB)
+	postRepo := repository.NewPostRepository(db.DB)
+	commentRepo := repository.NewCommentRepository(db.DB)
+	friendRepo := repository.NewFriendshipRepository(db.DB)
+	followRepo := repository.NewFollowRepository(db.DB)
+	messageRepo := repository.NewMessageRepository(db.DB)
+	groupRepo := repository.NewGroupRepository(db.DB)
+
+	notifQueue := make(chan *model.Notification, 1000)
+	hub := realtime.NewHub(time.Minute, 500)
+
+	blockService := NewBlockService(friendRepo)
+	userMuteService := NewUserMuteService(repository.NewUserMuteRepository(db.DB), userRepo, blockService)
+	mentionService := NewMentionService(repository.NewMentionRepository(db.DB), userRepo, blockService, notifQueue)
+	reactionService := NewReactionService(repository.NewReactionRepository(db.DB), postRepo, commentRepo, messageRepo,
+		userRepo, blockService, nil, notifQueue)
+	pollService := NewPollService(repository.NewPollRepository(db.DB), postRepo, userRepo, notifQueue)
+	mediaService := NewMediaService(repository.NewMediaRepository(db.DB), dir)
+	previewService := NewLinkPreviewService(repository.NewLinkPreviewRepository(db.DB),
+		security.NewSafeHTTPClient(time.Second, false), dir, make(chan string, 100))
+	bookmarkService := NewBookmarkService(repository.NewBookmarkRepository(db.DB), postRepo)
+	mutedWordService := NewMutedWordService(repository.NewMutedWordRepository(db.DB))
+	followService := NewFollowService(followRepo, userRepo, friendRepo, blockService, notifQueue)
+	userService := NewUserService(userRepo, friendRepo, followService, blockService)
+	postService := NewPostService(postRepo, commentRepo, userRepo, mentionService, reactionService, pollService,
+		mediaService, previewService, bookmarkService, mutedWordService, blockService, userMuteService, notifQueue)
+	socialService := NewSocialService(friendRepo, followRepo, blockService, commentRepo, postRepo, userRepo,
+		mentionService, reactionService, notifQueue)
+
+	return &testEnv{
+		t:          t,
+		db:         db.DB,
+		userRepo:   userRepo,
+		friendRepo: friendRepo,
+		blocks:     blockService,
+		users:      userService,
+		posts:      postService,
+		social:     socialService,
+		reactions:  reactionService,
+		messages: NewMessageService(messageRepo, friendRepo, blockService, userService, socialService, userRepo,
+			mentionService, reactionService, hub, notifQueue),
+		groups: NewGroupService(groupRepo, userRepo, mentionService, mediaService, mutedWordService, blockService,
+			notifQueue),
+		search:     NewSearchService(repository.NewSearchRepository(db.DB), postService, socialService, userRepo, groupRepo),
+		notifQueue: notifQueue,
+	}
+}
+
+// createUser adds a user with a private account, the default for sign-ups.
+func (e *testEnv) createUser(username string) int64 {
+	e.t.Helper()
+
+	id, err := e.userRepo.Create(&model.User{
+		Email:    username + "@example.com",
+		Username: username,
+		FullName: username,
+	})
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	return id
+}
+
+func (e *testEnv) makePublic(userID int64) {
+	e.t.Helper()
+
+	private := false
+	err := e.users.UpdatePrivacySettings(userID, &model.UserPrivacySettings{
+		ShowLastSeen:      "all",
+		AllowMessagesFrom: "all",
+		PrivateAccount:    &private,
+	})
+	if err != nil {
+		e.t.Fatal(err)
+	}
+}
+
+func (e *testEnv) befriend(userID1, userID2 int64) {
+	e.t.Helper()
+
+	id, err := e.friendRepo.CreateRequest(userID1, userID2)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	if err := e.social.AcceptFriendRequest(id, userID2); err != nil {
+		e.t.Fatal(err)
+	}
+}
+
+func (e *testEnv) block(blockerID, blockedID int64) {
+	e.t.Helper()
+
+	if err := e.social.BlockUserByID(blockerID, blockedID); err != nil {
+		e.t.Fatal(err)
+	}
+}
+
+func (e *testEnv) createPost(userID int64, content string) *model.Post {
+	e.t.Helper()
+
+	post, err := e.posts.CreatePost(userID, &model.PostCreate{Content: content})
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	return post
+}
+
+// drainNotifications empties the queue and returns what was in it.
+func (e *testEnv) drainNotifications() []*model.Notification {
+	var notifications []*model.Notification
+	for {
+		select {
+		case n := <-e.notifQueue:
+			notifications = append(notifications, n)
+		default:
+			return notifications
+		}
+	}
+}
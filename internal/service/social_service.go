@@ -2,30 +2,41 @@ package service
 
 import (
 	"errors"
+	"socialnet/internal/markdown"
 	"socialnet/internal/model"
 	"socialnet/internal/repository"
 	"socialnet/internal/security"
 )
 
+const maxCommentDepth = 4
+
 type SocialService struct {
-	friendRepo  *repository.FriendshipRepository
-	likeRepo    *repository.LikeRepository
-	commentRepo *repository.CommentRepository
-	postRepo    *repository.PostRepository
-	userRepo    *repository.UserRepository
-	notifQueue  chan *model.Notification
+	friendRepo      *repository.FriendshipRepository
+	followRepo      *repository.FollowRepository
+	blockService    *BlockService
+	commentRepo     *repository.CommentRepository
+	postRepo        *repository.PostRepository
+	userRepo        *repository.UserRepository
+	mentionService  *MentionService
+	reactionService *ReactionService
+	notifQueue      chan *model.Notification
 }
 
-func NewSocialService(friendRepo *repository.FriendshipRepository, likeRepo *repository.LikeRepository,
-	commentRepo *repository.CommentRepository, postRepo *repository.PostRepository,
-	userRepo *repository.UserRepository, notifQueue chan *model.Notification) *SocialService {
+func NewSocialService(friendRepo *repository.FriendshipRepository, followRepo *repository.FollowRepository,
+	blockService *BlockService, commentRepo *repository.CommentRepository,
+	postRepo *repository.PostRepository, userRepo *repository.UserRepository,
+	mentionService *MentionService, reactionService *ReactionService,
+	notifQueue chan *model.Notification) *SocialService {
 	return &SocialService{
-		friendRepo:  friendRepo,
-		likeRepo:    likeRepo,
-		commentRepo: commentRepo,
-		postRepo:    postRepo,
-		userRepo:    userRepo,
-		notifQueue:  notifQueue,
+		friendRepo:      friendRepo,
+		followRepo:      followRepo,
+		blockService:    blockService,
+		commentRepo:     commentRepo,
+		postRepo:        postRepo,
+		userRepo:        userRepo,
+		mentionService:  mentionService,
+		reactionService: reactionService,
+		notifQueue:      notifQueue,
 	}
 }
 
@@ -38,9 +49,18 @@ func (s *SocialService) SendFriendRequest(requesterID, addresseeID int64) error
 		return errors.New("user not found")
 	}
 
-	areFriends, _ := s.friendRepo.AreFriends(requesterID, addresseeID)
-	if areFriends {
-		return errors.New("already friends")
+	if existing, _ := s.friendRepo.GetFriendship(requesterID, addresseeID); existing != nil {
+		switch {
+		case existing.Status == model.FriendshipAccepted:
+			return errors.New("already friends")
+		case existing.Status == model.FriendshipBlocked:
+			return errors.New("user not found")
+		case existing.RequesterID == requesterID:
+			return errors.New("friend request already sent")
+		default:
+			// They already asked us, so sending back accepts their request.
+			return s.friendRepo.UpdateStatus(existing.ID, model.FriendshipAccepted)
+		}
 	}
 
 	id, err := s.friendRepo.CreateRequest(requesterID, addresseeID)
@@ -56,45 +76,118 @@ func (s *SocialService) SendFriendRequest(requesterID, addresseeID int64) error
 		Type:     model.NotificationFriendRequest,
 		TargetID: id,
 		Message:  message,
+		ActorID:  requesterID,
 	}
 
 	return nil
 }
 
-func (s *SocialService) AcceptFriendRequest(requestID, userID int64) error {
+// transition loads a friendship and checks that userID may apply action to it.
+func (s *SocialService) transition(requestID, userID int64, action model.FriendshipAction) (*model.Friendship, error) {
 	friendship, err := s.friendRepo.GetByID(requestID)
-	if err != nil {
-		return err
+	if err != nil || (friendship.RequesterID != userID && friendship.AddresseeID != userID) {
+		return nil, errors.New("friend request not found")
 	}
 
-	if friendship.AddresseeID != userID {
-		return errors.New("unauthorized")
+	if !friendship.CanApply(action, userID) {
+		return nil, errors.New("cannot " + string(action) + " this friend request")
 	}
+	return friendship, nil
+}
 
-	if friendship.Status != model.FriendshipPending {
-		return errors.New("request already processed")
+func (s *SocialService) AcceptFriendRequest(requestID, userID int64) error {
+	if _, err := s.transition(requestID, userID, model.FriendshipAccept); err != nil {
+		return err
 	}
 
 	return s.friendRepo.UpdateStatus(requestID, model.FriendshipAccepted)
 }
 
+// RemoveFriendRequest declines an incoming request or cancels an outgoing one,
+// depending on which side of it userID is.
+func (s *SocialService) RemoveFriendRequest(requestID, userID int64) error {
+	action := model.FriendshipDecline
+	if friendship, err := s.friendRepo.GetByID(requestID); err == nil && friendship.RequesterID == userID {
+		action = model.FriendshipCancel
+	}
+
+	if _, err := s.transition(requestID, userID, action); err != nil {
+		return err
+	}
+
+	return s.friendRepo.Delete(requestID)
+}
+
+func (s *SocialService) Unfriend(userID, friendID int64) error {
+	friendship, _ := s.friendRepo.GetFriendship(userID, friendID)
+	if friendship == nil || !friendship.CanApply(model.FriendshipUnfriend, userID) {
+		return errors.New("not friends")
+	}
+
+	return s.friendRepo.Delete(friendship.ID)
+}
+
+// BlockUser blocks the other side of a friend request. It is kept for clients
+// that block from the request list; BlockUserByID works without a request.
 func (s *SocialService) BlockUser(requestID, userID int64) error {
 	friendship, err := s.friendRepo.GetByID(requestID)
-	if err != nil {
+	if err != nil || (friendship.RequesterID != userID && friendship.AddresseeID != userID) {
+		return errors.New("friend request not found")
+	}
+
+	otherID := friendship.RequesterID
+	if otherID == userID {
+		otherID = friendship.AddresseeID
+	}
+	return s.BlockUserByID(userID, otherID)
+}
+
+// BlockUserByID blocks targetID from any state and drops follows between the
+// two. The block stands on its own even if targetID has also blocked userID.
+func (s *SocialService) BlockUserByID(userID, targetID int64) error {
+	if userID == targetID {
+		return errors.New("cannot block yourself")
+	}
+
+	if _, err := s.userRepo.GetByID(targetID); err != nil {
+		return errors.New("user not found")
+	}
+
+	if existing, _ := s.friendRepo.GetBlockedBy(userID, targetID); existing != nil {
+		return nil
+	}
+
+	if err := s.friendRepo.Block(userID, targetID); err != nil {
 		return err
 	}
 
-	if friendship.AddresseeID != userID {
-		return errors.New("unauthorized")
+	return s.followRepo.DeleteBetween(userID, targetID)
+}
+
+func (s *SocialService) UnblockUser(userID, targetID int64) error {
+	friendship, _ := s.friendRepo.GetBlockedBy(userID, targetID)
+	if friendship == nil || !friendship.CanApply(model.FriendshipUnblock, userID) {
+		return errors.New("user is not blocked")
 	}
 
-	return s.friendRepo.UpdateStatus(requestID, model.FriendshipBlocked)
+	return s.friendRepo.Delete(friendship.ID)
+}
+
+func (s *SocialService) GetBlockedUsers(userID int64) ([]*model.User, error) {
+	return s.friendRepo.GetBlockedUsers(userID)
 }
 
 func (s *SocialService) GetFriends(userID int64) ([]*model.User, error) {
 	return s.friendRepo.GetFriends(userID)
 }
 
+func (s *SocialService) GetMutualFriends(userID, otherID int64) ([]*model.User, error) {
+	if _, err := s.userRepo.GetByID(otherID); err != nil || s.blockService.IsBlocked(userID, otherID) {
+		return nil, errors.New("user not found")
+	}
+	return s.friendRepo.GetMutualFriends(userID, otherID)
+}
+
 func (s *SocialService) GetPendingRequests(userID int64) ([]*model.Friendship, error) {
 	friendships, err := s.friendRepo.GetPendingRequests(userID)
 	if err != nil {
@@ -109,43 +202,32 @@ func (s *SocialService) GetPendingRequests(userID int64) ([]*model.Friendship, e
 	return friendships, nil
 }
 
-func (s *SocialService) LikePost(postID, userID int64) error {
-	post, err := s.postRepo.GetByID(postID)
+func (s *SocialService) GetOutgoingRequests(userID int64) ([]*model.Friendship, error) {
+	friendships, err := s.friendRepo.GetOutgoingRequests(userID)
 	if err != nil {
-		return err
-	}
-
-	liked, _ := s.likeRepo.HasUserLiked(postID, userID)
-	if liked {
-		return errors.New("already liked")
-	}
-
-	like := &model.Like{
-		PostID: postID,
-		UserID: userID,
+		return nil, err
 	}
 
-	if err := s.likeRepo.Create(like); err != nil {
-		return err
+	for _, friendship := range friendships {
+		addressee, _ := s.userRepo.GetByID(friendship.AddresseeID)
+		friendship.Addressee = addressee
 	}
 
-	if post.UserID != userID {
-		liker, _ := s.userRepo.GetByID(userID)
-		message := liker.Username + " liked your post"
+	return friendships, nil
+}
 
-		s.notifQueue <- &model.Notification{
-			UserID:   post.UserID,
-			Type:     model.NotificationLike,
-			TargetID: postID,
-			Message:  message,
-		}
+// LikePost and UnlikePost keep the original like endpoints working on top of
+// reactions.
+func (s *SocialService) LikePost(postID, userID int64) error {
+	_, _, mine := s.reactionService.Summarize(model.ReactionTargetPost, postID, userID)
+	if mine != "" {
+		return errors.New("already liked")
 	}
-
-	return nil
+	return s.reactionService.React(model.ReactionTargetPost, postID, userID, model.ReactionLike)
 }
 
 func (s *SocialService) UnlikePost(postID, userID int64) error {
-	return s.likeRepo.Delete(postID, userID)
+	return s.reactionService.RemoveReaction(model.ReactionTargetPost, postID, userID)
 }
 
 func (s *SocialService) CommentOnPost(postID, userID int64, create *model.CommentCreate) (*model.Comment, error) {
@@ -158,12 +240,32 @@ func (s *SocialService) CommentOnPost(postID, userID int64, create *model.Commen
 		return nil, err
 	}
 
+	if visible, _ := s.postRepo.IsVisibleTo(postID, userID); !visible {
+		return nil, errors.New("post not found")
+	}
+
 	comment := &model.Comment{
 		PostID:  postID,
 		UserID:  userID,
 		Content: create.Content,
 	}
 
+	var parent *model.Comment
+	if create.ParentID != 0 {
+		parent, err = s.commentRepo.GetByID(create.ParentID)
+		if err != nil || parent.PostID != postID || s.blockService.IsBlocked(parent.UserID, userID) {
+			return nil, errors.New("parent comment not found")
+		}
+		if parent.Deleted {
+			return nil, errors.New("cannot reply to a deleted comment")
+		}
+		if parent.Depth >= maxCommentDepth {
+			return nil, errors.New("maximum reply depth reached")
+		}
+		comment.ParentID = &parent.ID
+		comment.Depth = parent.Depth + 1
+	}
+
 	id, err := s.commentRepo.Create(comment)
 	if err != nil {
 		return nil, err
@@ -176,8 +278,20 @@ func (s *SocialService) CommentOnPost(postID, userID int64, create *model.Commen
 
 	author, _ := s.userRepo.GetByID(comment.UserID)
 	comment.Author = author
+	comment.ContentHTML = markdown.Render(comment.Content)
 
-	if post.UserID != userID {
+	if parent != nil && parent.UserID != userID {
+		s.notifQueue <- &model.Notification{
+			UserID:   parent.UserID,
+			Type:     model.NotificationReply,
+			TargetID: postID,
+			Message:  author.Username + " replied to your comment",
+			Text:     comment.Content,
+			ActorID:  userID,
+		}
+	}
+
+	if post.UserID != userID && (parent == nil || parent.UserID != post.UserID) {
 		commenter, _ := s.userRepo.GetByID(userID)
 		message := commenter.Username + " commented on your post"
 
@@ -186,22 +300,182 @@ func (s *SocialService) CommentOnPost(postID, userID int64, create *model.Commen
 			Type:     model.NotificationComment,
 			TargetID: postID,
 			Message:  message,
+			Text:     comment.Content,
+			ActorID:  userID,
 		}
 	}
 
+	s.mentionService.ProcessMentions(userID, model.MentionTargetComment, comment.ID, postID, comment.Content,
+		postVisibility(s.postRepo, postID))
+
+	return comment, nil
+}
+
+func (s *SocialService) UpdateComment(commentID, userID int64, update *model.CommentUpdate) (*model.Comment, error) {
+	if err := security.ValidateContent(update.Content, 1000); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.commentRepo.GetByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.UserID != userID {
+		return nil, errors.New("unauthorized")
+	}
+
+	if comment.Deleted {
+		return nil, errors.New("comment not found")
+	}
+
+	if err := s.commentRepo.Update(commentID, update.Content); err != nil {
+		return nil, err
+	}
+
+	s.mentionService.ProcessMentions(userID, model.MentionTargetComment, commentID, comment.PostID, update.Content,
+		postVisibility(s.postRepo, comment.PostID))
+
+	comment, err = s.commentRepo.GetByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	author, _ := s.userRepo.GetByID(comment.UserID)
+	comment.Author = author
+	comment.ContentHTML = markdown.Render(comment.Content)
+
 	return comment, nil
 }
 
-func (s *SocialService) GetComments(postID int64) ([]*model.Comment, error) {
+// DeleteComment lets the comment author, the post owner or an admin remove a
+// comment. A comment that has replies is blanked instead so the thread below
+// it survives.
+func (s *SocialService) DeleteComment(commentID, userID int64, isAdmin bool) error {
+	comment, err := s.commentRepo.GetByID(commentID)
+	if err != nil {
+		return err
+	}
+
+	if comment.UserID != userID && !isAdmin {
+		post, err := s.postRepo.GetByID(comment.PostID)
+		if err != nil || post.UserID != userID {
+			return errors.New("unauthorized")
+		}
+	}
+
+	hasReplies, _ := s.commentRepo.HasReplies(commentID)
+	if hasReplies {
+		if comment.Deleted {
+			return errors.New("comment not found")
+		}
+		if err := s.commentRepo.SoftDelete(commentID); err != nil {
+			return err
+		}
+		// Blanking the comment keeps its row, so the delete trigger that
+		// clears mentions does not fire.
+		return s.mentionService.RemoveMentions(model.MentionTargetComment, commentID)
+	}
+
+	if err := s.commentRepo.Delete(commentID); err != nil {
+		return err
+	}
+
+	// Drop blanked ancestors that no longer hold any replies.
+	for parentID := comment.ParentID; parentID != nil; {
+		parent, err := s.commentRepo.GetByID(*parentID)
+		if err != nil || !parent.Deleted {
+			break
+		}
+		if hasReplies, _ := s.commentRepo.HasReplies(parent.ID); hasReplies {
+			break
+		}
+		if err := s.commentRepo.Delete(parent.ID); err != nil {
+			return err
+		}
+		parentID = parent.ParentID
+	}
+
+	return nil
+}
+
+// GetComments returns a post's comments in thread order: every reply follows
+// its parent, and siblings are oldest first. Clients indent by Depth. Comments
+// by users blocked either way with the viewer stay as hidden placeholders so
+// replies to them keep their place.
+func (s *SocialService) GetComments(postID, viewerID int64) ([]*model.Comment, error) {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, errors.New("post not found")
+	}
+	if post.UserID != viewerID {
+		if visible, _ := s.postRepo.IsVisibleTo(postID, viewerID); !visible {
+			return nil, errors.New("post not found")
+		}
+	}
+
 	comments, err := s.commentRepo.GetByPostID(postID)
 	if err != nil {
 		return nil, err
 	}
 
+	blocked, err := s.blockService.BlockedIDs(viewerID)
+	if err != nil {
+		return nil, err
+	}
 	for _, comment := range comments {
-		author, _ := s.userRepo.GetByID(comment.UserID)
-		comment.Author = author
+		if blocked[comment.UserID] {
+			comment.Hidden = true
+			comment.UserID = 0
+			comment.Content = ""
+		}
+	}
+
+	children := make(map[int64][]*model.Comment)
+	var roots []*model.Comment
+	for _, comment := range comments {
+		if comment.ParentID == nil {
+			roots = append(roots, comment)
+		} else {
+			children[*comment.ParentID] = append(children[*comment.ParentID], comment)
+		}
+	}
+
+	ordered := make([]*model.Comment, 0, len(comments))
+	var walk func(list []*model.Comment)
+	walk = func(list []*model.Comment) {
+		for _, comment := range list {
+			comment.ReplyCount = len(children[comment.ID])
+			if !comment.Deleted && !comment.Hidden {
+				s.hydrateComment(comment, viewerID)
+			}
+			ordered = append(ordered, comment)
+			walk(children[comment.ID])
+		}
+	}
+	walk(roots)
+
+	return ordered, nil
+}
+
+// GetComment returns one comment, provided the viewer can see it and the post
+// it belongs to.
+func (s *SocialService) GetComment(commentID, viewerID int64) (*model.Comment, error) {
+	comment, err := s.commentRepo.GetByID(commentID)
+	if err != nil || comment.Deleted || s.blockService.IsBlocked(comment.UserID, viewerID) {
+		return nil, errors.New("comment not found")
+	}
+	if visible, _ := s.postRepo.IsVisibleTo(comment.PostID, viewerID); !visible {
+		return nil, errors.New("comment not found")
 	}
 
-	return comments, nil
+	s.hydrateComment(comment, viewerID)
+	return comment, nil
+}
+
+func (s *SocialService) hydrateComment(comment *model.Comment, viewerID int64) {
+	author, _ := s.userRepo.GetByID(comment.UserID)
+	comment.Author = author
+	comment.ContentHTML = markdown.Render(comment.Content)
+	comment.Reactions, _, comment.MyReaction = s.reactionService.Summarize(model.ReactionTargetComment, comment.ID, viewerID)
 }
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"socialnet/internal/model"
+	"socialnet/internal/repository"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type StoryService struct {
+	storyRepo       *repository.StoryRepository
+	friendRepo      *repository.FriendshipRepository
+	userRepo        *repository.UserRepository
+	mediaService    *MediaService
+	messageService  *MessageService
+	userMuteService *UserMuteService
+	ttl             time.Duration
+}
+
+func NewStoryService(storyRepo *repository.StoryRepository, friendRepo *repository.FriendshipRepository,
+	userRepo *repository.UserRepository, mediaService *MediaService, messageService *MessageService,
+	userMuteService *UserMuteService, ttl time.Duration) *StoryService {
+	return &StoryService{
+		storyRepo:       storyRepo,
+		friendRepo:      friendRepo,
+		userRepo:        userRepo,
+		mediaService:    mediaService,
+		messageService:  messageService,
+		userMuteService: userMuteService,
+		ttl:             ttl,
+	}
+}
+
+func (s *StoryService) CreateStory(userID int64, create *model.StoryCreate) (*model.Story, error) {
+	content := strings.TrimSpace(create.Content)
+	if content == "" && create.MediaURL == "" {
+		return nil, errors.New("story needs text or an image")
+	}
+	if utf8.RuneCountInString(content) > model.MaxStoryLength {
+		return nil, errors.New("content exceeds maximum length")
+	}
+
+	if err := s.mediaService.ValidateMediaURL(userID, create.MediaURL); err != nil {
+		return nil, err
+	}
+
+	story := &model.Story{
+		UserID:    userID,
+		Content:   content,
+		MediaURL:  create.MediaURL,
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+
+	id, err := s.storyRepo.Create(story)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetStory(id, userID)
+}
+
+// GetTray lists the active stories of the viewer's friends, one entry per
+// author, leaving out users the viewer mutes. The viewer's own stories come
+// first, then authors with unseen stories, each group ordered by its latest
+// story.
+func (s *StoryService) GetTray(viewerID int64) ([]*model.StoryTrayEntry, error) {
+	stories, err := s.storyRepo.GetTray(viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	mutedUsers := s.userMuteService.MutedIDs(viewerID)
+
+	var tray []*model.StoryTrayEntry
+	var current *model.StoryTrayEntry
+	for _, story := range stories {
+		if mutedUsers[story.UserID] {
+			continue
+		}
+		if current == nil || current.User.ID != story.UserID {
+			user, err := s.userRepo.GetByID(story.UserID)
+			if err != nil {
+				continue
+			}
+			current = &model.StoryTrayEntry{User: user}
+			tray = append(tray, current)
+		}
+
+		if story.UserID == viewerID {
+			story.Viewed = true
+		}
+		current.HasUnseen = current.HasUnseen || !story.Viewed
+		current.Stories = append(current.Stories, story)
+	}
+
+	sort.SliceStable(tray, func(i, j int) bool {
+		a, b := tray[i], tray[j]
+		if (a.User.ID == viewerID) != (b.User.ID == viewerID) {
+			return a.User.ID == viewerID
+		}
+		if a.HasUnseen != b.HasUnseen {
+			return a.HasUnseen
+		}
+		return latestStory(a).After(latestStory(b))
+	})
+
+	return tray, nil
+}
+
+func latestStory(entry *model.StoryTrayEntry) time.Time {
+	return entry.Stories[len(entry.Stories)-1].CreatedAt
+}
+
+// GetStory returns a story and records the view. Authors see their view
+// count instead.
+func (s *StoryService) GetStory(storyID, viewerID int64) (*model.Story, error) {
+	story, err := s.getVisible(storyID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	story.Author, _ = s.userRepo.GetByID(story.UserID)
+	story.Viewed = true
+
+	if story.UserID == viewerID {
+		count, _ := s.storyRepo.GetViewCount(story.ID)
+		story.ViewCount = &count
+		return story, nil
+	}
+
+	if err := s.storyRepo.AddView(story.ID, viewerID); err != nil {
+		return nil, err
+	}
+	return story, nil
+}
+
+func (s *StoryService) GetViews(storyID, userID int64) ([]*model.StoryView, error) {
+	story, err := s.storyRepo.GetActiveByID(storyID)
+	if err != nil {
+		return nil, err
+	}
+	if story.UserID != userID {
+		return nil, errors.New("unauthorized")
+	}
+
+	views, err := s.storyRepo.GetViews(storyID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, view := range views {
+		view.User, _ = s.userRepo.GetByID(view.UserID)
+	}
+	return views, nil
+}
+
+func (s *StoryService) Reply(storyID, userID int64, reply *model.StoryReply) (*model.Message, error) {
+	story, err := s.getVisible(storyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if story.UserID == userID {
+		return nil, errors.New("cannot reply to your own story")
+	}
+
+	return s.messageService.SendStoryReply(userID, story.UserID, story.ID, reply.Body)
+}
+
+func (s *StoryService) DeleteStory(storyID, userID int64) error {
+	story, err := s.storyRepo.GetActiveByID(storyID)
+	if err != nil {
+		return err
+	}
+	if story.UserID != userID {
+		return errors.New("unauthorized")
+	}
+
+	return s.purge(story)
+}
+
+// PurgeExpired deletes expired stories along with uploads nothing else uses.
+func (s *StoryService) PurgeExpired() (int, error) {
+	purged := 0
+	for {
+		stories, err := s.storyRepo.GetExpired(100)
+		if err != nil {
+			return purged, err
+		}
+
+		for _, story := range stories {
+			if err := s.purge(story); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+
+		if len(stories) < 100 {
+			return purged, nil
+		}
+	}
+}
+
+func (s *StoryService) purge(story *model.Story) error {
+	if err := s.storyRepo.Delete(story.ID); err != nil {
+		return err
+	}
+
+	if story.MediaURL != "" {
+		if err := s.mediaService.DeleteIfUnused(story.MediaURL); err != nil {
+			log.Printf("Failed to delete story media %s: %v", story.MediaURL, err)
+		}
+	}
+	return nil
+}
+
+func (s *StoryService) getVisible(storyID, viewerID int64) (*model.Story, error) {
+	story, err := s.storyRepo.GetActiveByID(storyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if story.UserID != viewerID {
+		if areFriends, _ := s.friendRepo.AreFriends(story.UserID, viewerID); !areFriends {
+			return nil, errors.New("story not found")
+		}
+	}
+	return story, nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"errors"
+	"socialnet/internal/model"
+	"socialnet/internal/repository"
+	"time"
+)
+
+type UserMuteService struct {
+	userMuteRepo *repository.UserMuteRepository
+	userRepo     *repository.UserRepository
+	blockService *BlockService
+}
+
+func NewUserMuteService(userMuteRepo *repository.UserMuteRepository, userRepo *repository.UserRepository,
+	blockService *BlockService) *UserMuteService {
+	return &UserMuteService{
+		userMuteRepo: userMuteRepo,
+		userRepo:     userRepo,
+		blockService: blockService,
+	}
+}
+
+// Mute mutes targetID for one of model.UserMuteDurations, or until unmuted
+// when the duration is empty. Muting again replaces the duration.
+func (s *UserMuteService) Mute(userID int64, create *model.UserMuteCreate) (*model.UserMute, error) {
+	if create.UserID == userID {
+		return nil, errors.New("cannot mute yourself")
+	}
+
+	if _, err := s.userRepo.GetByID(create.UserID); err != nil || s.blockService.IsBlocked(userID, create.UserID) {
+		return nil, errors.New("user not found")
+	}
+
+	mute := &model.UserMute{UserID: userID, MutedUserID: create.UserID}
+	if create.Duration != "" {
+		duration, ok := model.UserMuteDurations[create.Duration]
+		if !ok {
+			return nil, errors.New("duration must be 24h, 7d or 30d")
+		}
+		expiresAt := time.Now().Add(duration)
+		mute.ExpiresAt = &expiresAt
+	}
+
+	id, err := s.userMuteRepo.Create(mute)
+	if err != nil {
+		return nil, err
+	}
+
+	mute, err = s.userMuteRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	mute.MutedUser, _ = s.userRepo.GetByID(mute.MutedUserID)
+	return mute, nil
+}
+
+func (s *UserMuteService) Unmute(userID, mutedUserID int64) error {
+	removed, err := s.userMuteRepo.Delete(userID, mutedUserID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return errors.New("user is not muted")
+	}
+	return nil
+}
+
+func (s *UserMuteService) GetMutes(userID int64) ([]*model.UserMute, error) {
+	mutes, err := s.userMuteRepo.GetActive(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, mute := range mutes {
+		mute.MutedUser, _ = s.userRepo.GetByID(mute.MutedUserID)
+	}
+	return mutes, nil
+}
+
+func (s *UserMuteService) IsMuted(userID, otherID int64) bool {
+	muted, _ := s.userMuteRepo.IsMuted(userID, otherID)
+	return muted
+}
+
+// MutedIDs returns the users userID currently mutes, for filtering lists.
+func (s *UserMuteService) MutedIDs(userID int64) map[int64]bool {
+	mutes, _ := s.userMuteRepo.GetActive(userID)
+
+	muted := make(map[int64]bool, len(mutes))
+	for _, mute := range mutes {
+		muted[mute.MutedUserID] = true
+	}
+	return muted
+}
+
+func (s *UserMuteService) PurgeExpired() error {
+	return s.userMuteRepo.DeleteExpired()
+}
@@ -4,17 +4,23 @@ import (
 	"errors"
 	"socialnet/internal/model"
 	"socialnet/internal/repository"
+	"strings"
 )
 
 type UserService struct {
-	userRepo   *repository.UserRepository
-	friendRepo *repository.FriendshipRepository
+	userRepo      *repository.UserRepository
+	friendRepo    *repository.FriendshipRepository
+	followService *FollowService
+	blockService  *BlockService
 }
 
-func NewUserService(userRepo *repository.UserRepository, friendRepo *repository.FriendshipRepository) *UserService {
+func NewUserService(userRepo *repository.UserRepository, friendRepo *repository.FriendshipRepository,
+	followService *FollowService, blockService *BlockService) *UserService {
 	return &UserService{
-		userRepo:   userRepo,
-		friendRepo: friendRepo,
+		userRepo:      userRepo,
+		friendRepo:    friendRepo,
+		followService: followService,
+		blockService:  blockService,
 	}
 }
 
@@ -23,6 +29,10 @@ func (s *UserService) GetProfile(userID int64) (*model.User, error) {
 }
 
 func (s *UserService) GetPublicProfile(targetID, viewerID int64) (*model.UserPublic, error) {
+	if s.blockService.IsBlocked(viewerID, targetID) {
+		return nil, errors.New("user not found")
+	}
+
 	user, err := s.userRepo.GetByID(targetID)
 	if err != nil {
 		return nil, err
@@ -36,7 +46,10 @@ func (s *UserService) GetPublicProfile(targetID, viewerID int64) (*model.UserPub
 		}
 	}
 
-	return user.ToPublic(viewerID, isFriend), nil
+	profile := user.ToPublic(viewerID, isFriend)
+	s.followService.Describe(profile, viewerID)
+
+	return profile, nil
 }
 
 func (s *UserService) UpdateProfile(userID int64, profile *model.UserProfile) error {
@@ -66,7 +79,26 @@ func (s *UserService) UpdatePrivacySettings(userID int64, settings *model.UserPr
 		return errors.New("invalid allow_messages_from value")
 	}
 
-	return s.userRepo.UpdatePrivacySettings(userID, settings)
+	if err := s.userRepo.UpdatePrivacySettings(userID, settings); err != nil {
+		return err
+	}
+
+	if settings.PrivateAccount != nil && !*settings.PrivateAccount {
+		return s.followService.AcceptPendingRequests(userID)
+	}
+	return nil
+}
+
+func (s *UserService) GetPreferences(userID int64) (*model.UserPreferences, error) {
+	return s.userRepo.GetPreferences(userID)
+}
+
+func (s *UserService) UpdatePreferences(userID int64, prefs *model.UserPreferences) error {
+	if prefs.SensitiveContent != model.SensitiveContentHide && prefs.SensitiveContent != model.SensitiveContentExpand {
+		return errors.New("invalid sensitive_content value")
+	}
+
+	return s.userRepo.UpdatePreferences(userID, prefs)
 }
 
 func (s *UserService) SetEmojiAvatar(userID int64, emojiID string) error {
@@ -77,7 +109,7 @@ func (s *UserService) SetEmojiAvatar(userID int64, emojiID string) error {
 	return s.userRepo.UpdateEmojiAvatar(userID, emojiID)
 }
 
-func (s *UserService) GetUsersWithEmoji(emojiID string) ([]*model.EmojiUserInfo, error) {
+func (s *UserService) GetUsersWithEmoji(emojiID string, viewerID int64) ([]*model.EmojiUserInfo, error) {
 	if !model.IsValidEmoji(emojiID) {
 		return nil, errors.New("invalid emoji")
 	}
@@ -86,6 +118,9 @@ func (s *UserService) GetUsersWithEmoji(emojiID string) ([]*model.EmojiUserInfo,
 	if err != nil {
 		return nil, err
 	}
+	if users, err = s.blockService.FilterUsers(users, viewerID); err != nil {
+		return nil, err
+	}
 
 	result := make([]*model.EmojiUserInfo, len(users))
 	for i, u := range users {
@@ -112,14 +147,49 @@ func (s *UserService) DeleteAccount(userID int64) error {
 	return s.userRepo.Delete(userID)
 }
 
-func (s *UserService) SearchUsers(searchTerm string) ([]*model.User, error) {
+func (s *UserService) SearchUsers(searchTerm string, viewerID int64) ([]*model.User, error) {
 	if searchTerm == "" {
 		return nil, errors.New("search term is required")
 	}
-	return s.userRepo.Search(searchTerm, 20)
+
+	users, err := s.userRepo.Search(searchTerm, 20)
+	if err != nil {
+		return nil, err
+	}
+	return s.blockService.FilterUsers(users, viewerID)
+}
+
+func (s *UserService) AutocompleteUsers(prefix string, viewerID int64) ([]*model.EmojiUserInfo, error) {
+	prefix = strings.TrimPrefix(strings.TrimSpace(prefix), "@")
+	if prefix == "" {
+		return []*model.EmojiUserInfo{}, nil
+	}
+
+	users, err := s.userRepo.SearchByUsernamePrefix(prefix, viewerID, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.EmojiUserInfo, 0, len(users))
+	for _, u := range users {
+		if u.ID == viewerID {
+			continue
+		}
+		result = append(result, &model.EmojiUserInfo{
+			UserID:      u.ID,
+			Username:    u.Username,
+			FullName:    u.FullName,
+			EmojiAvatar: u.EmojiAvatar,
+		})
+	}
+	return result, nil
 }
 
 func (s *UserService) CanMessageUser(senderID, recipientID int64) (bool, error) {
+	if s.blockService.IsBlocked(senderID, recipientID) {
+		return false, errors.New("user not found")
+	}
+
 	recipient, err := s.userRepo.GetByID(recipientID)
 	if err != nil {
 		return false, err
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"socialnet/internal/model"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// wordMatcher checks text against one user's muted words. Single words and
+// hashtags are looked up in maps, so the cost of matching grows with the
+// length of the text rather than the number of rules; only multi-word and
+// substring rules are scanned one by one.
+type wordMatcher struct {
+	words      map[string]string
+	tags       map[string]string
+	phrases    []mutePattern
+	substrings []mutePattern
+	expiresAt  *time.Time
+}
+
+type mutePattern struct {
+	pattern string
+	phrase  string
+}
+
+func newWordMatcher(words []*model.MutedWord) *wordMatcher {
+	m := &wordMatcher{
+		words: make(map[string]string),
+		tags:  make(map[string]string),
+	}
+
+	for _, word := range words {
+		if word.ExpiresAt != nil && (m.expiresAt == nil || word.ExpiresAt.Before(*m.expiresAt)) {
+			m.expiresAt = word.ExpiresAt
+		}
+
+		if strings.HasPrefix(word.Phrase, "#") {
+			m.tags[word.Phrase] = word.Phrase
+			continue
+		}
+
+		tokens, _ := tokenizeWords(word.Phrase)
+		switch {
+		case !word.WholeWord || len(tokens) == 0:
+			m.substrings = append(m.substrings, mutePattern{pattern: word.Phrase, phrase: word.Phrase})
+		case len(tokens) == 1:
+			m.words[tokens[0]] = word.Phrase
+		default:
+			m.phrases = append(m.phrases, mutePattern{pattern: " " + strings.Join(tokens, " ") + " ", phrase: word.Phrase})
+		}
+	}
+
+	return m
+}
+
+func (m *wordMatcher) empty() bool {
+	return len(m.words) == 0 && len(m.tags) == 0 && len(m.phrases) == 0 && len(m.substrings) == 0
+}
+
+// valid reports whether none of the rules the matcher was built from has
+// expired since.
+func (m *wordMatcher) valid(now time.Time) bool {
+	return m.expiresAt == nil || now.Before(*m.expiresAt)
+}
+
+// Match returns the muted phrases found in any of the texts.
+func (m *wordMatcher) Match(texts ...string) []string {
+	if m.empty() {
+		return nil
+	}
+
+	var matched []string
+	seen := make(map[string]bool)
+	add := func(phrase string) {
+		if !seen[phrase] {
+			seen[phrase] = true
+			matched = append(matched, phrase)
+		}
+	}
+
+	for _, text := range texts {
+		if text == "" {
+			continue
+		}
+		lower := strings.ToLower(text)
+
+		words, tags := tokenizeWords(lower)
+		for _, word := range words {
+			if phrase, ok := m.words[word]; ok {
+				add(phrase)
+			}
+		}
+		for _, tag := range tags {
+			if phrase, ok := m.tags[tag]; ok {
+				add(phrase)
+			}
+		}
+
+		if len(m.phrases) > 0 {
+			joined := " " + strings.Join(words, " ") + " "
+			for _, p := range m.phrases {
+				if strings.Contains(joined, p.pattern) {
+					add(p.phrase)
+				}
+			}
+		}
+
+		for _, p := range m.substrings {
+			if strings.Contains(lower, p.pattern) {
+				add(p.phrase)
+			}
+		}
+	}
+
+	return matched
+}
+
+// tokenizeWords splits lowercased text into words made of letters, digits and
+// underscores. Words written as hashtags are also returned in tags, with
+// their leading #.
+func tokenizeWords(text string) (words, tags []string) {
+	start := -1
+	hashtag := false
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		word := text[start:end]
+		words = append(words, word)
+		if hashtag {
+			tags = append(tags, "#"+word)
+		}
+		start = -1
+	}
+
+	prev := rune(0)
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			if start < 0 {
+				start = i
+				hashtag = prev == '#'
+			}
+		} else {
+			flush(i)
+		}
+		prev = r
+	}
+	flush(len(text))
+
+	return words, tags
+}
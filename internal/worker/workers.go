@@ -55,3 +55,230 @@ func (w *CleanupWorker) Start() {
 		}
 	}()
 }
+
+// SchedulerWorker publishes scheduled posts once they fall due. It runs once
+// at startup so posts that came due while the server was down are not held
+// back until the first tick.
+type SchedulerWorker struct {
+	service  *service.PostService
+	interval time.Duration
+}
+
+func NewSchedulerWorker(service *service.PostService, interval time.Duration) *SchedulerWorker {
+	return &SchedulerWorker{
+		service:  service,
+		interval: interval,
+	}
+}
+
+func (w *SchedulerWorker) Start() {
+	go func() {
+		log.Println("Scheduler worker started")
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			published, err := w.service.PublishDuePosts()
+			if err != nil {
+				log.Printf("Failed to publish scheduled posts: %v", err)
+			}
+			if published > 0 {
+				log.Printf("Published %d scheduled posts", published)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// PollWorker notifies authors when their polls close.
+type PollWorker struct {
+	service  *service.PollService
+	interval time.Duration
+}
+
+func NewPollWorker(service *service.PollService, interval time.Duration) *PollWorker {
+	return &PollWorker{
+		service:  service,
+		interval: interval,
+	}
+}
+
+func (w *PollWorker) Start() {
+	go func() {
+		log.Println("Poll worker started")
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := w.service.NotifyClosedPolls(); err != nil {
+				log.Printf("Failed to notify closed polls: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// LinkPreviewWorker fetches queued link previews. The sweep, run once at
+// startup and then on every tick, covers URLs left pending by a restart or a
+// full queue.
+type LinkPreviewWorker struct {
+	service  *service.LinkPreviewService
+	queue    chan string
+	interval time.Duration
+}
+
+func NewLinkPreviewWorker(service *service.LinkPreviewService, queue chan string, interval time.Duration) *LinkPreviewWorker {
+	return &LinkPreviewWorker{
+		service:  service,
+		queue:    queue,
+		interval: interval,
+	}
+}
+
+func (w *LinkPreviewWorker) Start() {
+	go func() {
+		log.Println("Link preview worker started")
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		w.sweep()
+		for {
+			select {
+			case link := <-w.queue:
+				if err := w.service.Process(link); err != nil {
+					log.Printf("Failed to process link preview: %v", err)
+				}
+			case <-ticker.C:
+				w.sweep()
+			}
+		}
+	}()
+}
+
+func (w *LinkPreviewWorker) sweep() {
+	if _, err := w.service.ProcessPending(); err != nil {
+		log.Printf("Failed to process pending link previews: %v", err)
+	}
+}
+
+// StoryCleanupWorker purges expired stories and the uploads only they used.
+// Expired stories are already hidden from every endpoint, so the interval
+// only affects how long their rows and files linger.
+type StoryCleanupWorker struct {
+	service  *service.StoryService
+	interval time.Duration
+}
+
+func NewStoryCleanupWorker(service *service.StoryService, interval time.Duration) *StoryCleanupWorker {
+	return &StoryCleanupWorker{
+		service:  service,
+		interval: interval,
+	}
+}
+
+func (w *StoryCleanupWorker) Start() {
+	go func() {
+		log.Println("Story cleanup worker started")
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			purged, err := w.service.PurgeExpired()
+			if err != nil {
+				log.Printf("Failed to purge expired stories: %v", err)
+			}
+			if purged > 0 {
+				log.Printf("Purged %d expired stories", purged)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// Expired muted words no longer match anything; this only removes their rows.
+type MutedWordCleanupWorker struct {
+	service  *service.MutedWordService
+	interval time.Duration
+}
+
+func NewMutedWordCleanupWorker(service *service.MutedWordService, interval time.Duration) *MutedWordCleanupWorker {
+	return &MutedWordCleanupWorker{
+		service:  service,
+		interval: interval,
+	}
+}
+
+func (w *MutedWordCleanupWorker) Start() {
+	go func() {
+		log.Println("Muted word cleanup worker started")
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := w.service.PurgeExpired(); err != nil {
+				log.Printf("Failed to purge expired muted words: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+type UserMuteCleanupWorker struct {
+	service  *service.UserMuteService
+	interval time.Duration
+}
+
+func NewUserMuteCleanupWorker(service *service.UserMuteService, interval time.Duration) *UserMuteCleanupWorker {
+	return &UserMuteCleanupWorker{
+		service:  service,
+		interval: interval,
+	}
+}
+
+func (w *UserMuteCleanupWorker) Start() {
+	go func() {
+		log.Println("User mute cleanup worker started")
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := w.service.PurgeExpired(); err != nil {
+				log.Printf("Failed to purge expired user mutes: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// FriendSuggestionWorker recomputes suggestions for the users whose friends
+// or groups changed since the last pass.
+type FriendSuggestionWorker struct {
+	service  *service.FriendSuggestionService
+	interval time.Duration
+}
+
+func NewFriendSuggestionWorker(service *service.FriendSuggestionService, interval time.Duration) *FriendSuggestionWorker {
+	return &FriendSuggestionWorker{
+		service:  service,
+		interval: interval,
+	}
+}
+
+func (w *FriendSuggestionWorker) Start() {
+	go func() {
+		log.Println("Friend suggestion worker started")
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			refreshed, err := w.service.RefreshStale()
+			if err != nil {
+				log.Printf("Failed to refresh friend suggestions: %v", err)
+			}
+			if refreshed > 0 {
+				log.Printf("Refreshed friend suggestions for %d users", refreshed)
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -10,6 +10,7 @@ import (
 	httpHandler "socialnet/internal/http/handler"
 	httpMiddleware "socialnet/internal/http/middleware"
 	"socialnet/internal/model"
+	"socialnet/internal/realtime"
 	"socialnet/internal/repository"
 	"socialnet/internal/security"
 	"socialnet/internal/service"
@@ -68,40 +69,77 @@ func main() {
 	userRepo := repository.NewUserRepository(db.DB)
 	postRepo := repository.NewPostRepository(db.DB)
 	commentRepo := repository.NewCommentRepository(db.DB)
-	likeRepo := repository.NewLikeRepository(db.DB)
+	reactionRepo := repository.NewReactionRepository(db.DB)
+	pollRepo := repository.NewPollRepository(db.DB)
+	mediaRepo := repository.NewMediaRepository(db.DB)
+	previewRepo := repository.NewLinkPreviewRepository(db.DB)
+	bookmarkRepo := repository.NewBookmarkRepository(db.DB)
+	storyRepo := repository.NewStoryRepository(db.DB)
+	mutedWordRepo := repository.NewMutedWordRepository(db.DB)
+	userMuteRepo := repository.NewUserMuteRepository(db.DB)
+	suggestionRepo := repository.NewFriendSuggestionRepository(db.DB)
 	friendRepo := repository.NewFriendshipRepository(db.DB)
+	followRepo := repository.NewFollowRepository(db.DB)
 	messageRepo := repository.NewMessageRepository(db.DB)
 	groupRepo := repository.NewGroupRepository(db.DB)
 	notifRepo := repository.NewNotificationRepository(db.DB)
 	reportRepo := repository.NewReportRepository(db.DB)
 	statsRepo := repository.NewStatsRepository(db.DB)
+	mentionRepo := repository.NewMentionRepository(db.DB)
+	searchRepo := repository.NewSearchRepository(db.DB)
 
 	notifQueue := make(chan *model.Notification, 100)
+	previewQueue := make(chan string, 100)
+	hub := realtime.NewHub(cfg.WebSocketResumeWindow, 500)
 
 	authService := service.NewAuthService(userRepo, firebaseAuth, cfg.InitialAdmins)
-	userService := service.NewUserService(userRepo, friendRepo)
-	postService := service.NewPostService(postRepo, likeRepo, userRepo)
-	socialService := service.NewSocialService(friendRepo, likeRepo, commentRepo, postRepo, userRepo, notifQueue)
-	messageService := service.NewMessageService(messageRepo, friendRepo, userRepo, notifQueue)
-	groupService := service.NewGroupService(groupRepo, userRepo, notifQueue)
-	notifService := service.NewNotificationService(notifRepo)
-	adminService := service.NewAdminService(reportRepo, postRepo, commentRepo, userRepo, statsRepo, notifQueue)
+	blockService := service.NewBlockService(friendRepo)
+	userMuteService := service.NewUserMuteService(userMuteRepo, userRepo, blockService)
+	mentionService := service.NewMentionService(mentionRepo, userRepo, blockService, notifQueue)
+	reactionService := service.NewReactionService(reactionRepo, postRepo, commentRepo, messageRepo, userRepo, blockService, cfg.Reactions, notifQueue)
+	pollService := service.NewPollService(pollRepo, postRepo, userRepo, notifQueue)
+	mediaService := service.NewMediaService(mediaRepo, cfg.UploadDir)
+	previewClient := security.NewSafeHTTPClient(cfg.LinkPreviewTimeout, cfg.LinkPreviewAllowPrivate)
+	previewService := service.NewLinkPreviewService(previewRepo, previewClient, cfg.UploadDir, previewQueue)
+	bookmarkService := service.NewBookmarkService(bookmarkRepo, postRepo)
+	mutedWordService := service.NewMutedWordService(mutedWordRepo)
+	followService := service.NewFollowService(followRepo, userRepo, friendRepo, blockService, notifQueue)
+	userService := service.NewUserService(userRepo, friendRepo, followService, blockService)
+	postService := service.NewPostService(postRepo, commentRepo, userRepo, mentionService, reactionService, pollService, mediaService, previewService, bookmarkService, mutedWordService, blockService, userMuteService, notifQueue)
+	socialService := service.NewSocialService(friendRepo, followRepo, blockService, commentRepo, postRepo, userRepo, mentionService, reactionService, notifQueue)
+	messageService := service.NewMessageService(messageRepo, friendRepo, blockService, userService, socialService, userRepo, mentionService, reactionService, hub, notifQueue)
+	storyService := service.NewStoryService(storyRepo, friendRepo, userRepo, mediaService, messageService, userMuteService, cfg.StoryTTL)
+	groupService := service.NewGroupService(groupRepo, userRepo, mentionService, mediaService, mutedWordService, blockService, notifQueue)
+	notifService := service.NewNotificationService(notifRepo, userRepo, mutedWordService, userMuteService)
+	adminService := service.NewAdminService(reportRepo, postRepo, commentRepo, groupRepo, userRepo, statsRepo, notifQueue)
+	searchService := service.NewSearchService(searchRepo, postService, socialService, userRepo, groupRepo)
+	suggestionService := service.NewFriendSuggestionService(suggestionRepo, userRepo, blockService)
 
 	authHandler := httpHandler.NewAuthHandler(authService, cfg.JWTSecret, cfg.SessionDuration)
 	userHandler := httpHandler.NewUserHandler(userService)
 	postHandler := httpHandler.NewPostHandler(postService)
 	socialHandler := httpHandler.NewSocialHandler(socialService)
+	reactionHandler := httpHandler.NewReactionHandler(reactionService)
+	mediaHandler := httpHandler.NewMediaHandler(mediaService, cfg.UploadDir)
+	bookmarkHandler := httpHandler.NewBookmarkHandler(bookmarkService)
+	storyHandler := httpHandler.NewStoryHandler(storyService)
+	mutedWordHandler := httpHandler.NewMutedWordHandler(mutedWordService)
+	followHandler := httpHandler.NewFollowHandler(followService)
+	userMuteHandler := httpHandler.NewUserMuteHandler(userMuteService)
+	suggestionHandler := httpHandler.NewFriendSuggestionHandler(suggestionService)
+	realtimeHandler := httpHandler.NewRealtimeHandler(hub, messageService, cfg.WebSocketPingInterval)
 	messageHandler := httpHandler.NewMessageHandler(messageService)
 	groupHandler := httpHandler.NewGroupHandler(groupService)
 	notifHandler := httpHandler.NewNotificationHandler(notifService)
 	adminHandler := httpHandler.NewAdminHandler(adminService)
+	searchHandler := httpHandler.NewSearchHandler(searchService)
 
 	authMiddleware := httpMiddleware.NewAuthMiddleware(cfg.JWTSecret)
 	rateLimiter := httpMiddleware.NewRateLimiter(cfg.RateLimitPerMin, time.Minute)
 
 	router := httpRouter.NewRouter(
 		authHandler, userHandler, postHandler, socialHandler,
-		messageHandler, groupHandler, notifHandler, adminHandler,
+		messageHandler, groupHandler, notifHandler, adminHandler, searchHandler, reactionHandler, mediaHandler, bookmarkHandler, storyHandler, mutedWordHandler, followHandler, userMuteHandler, suggestionHandler, realtimeHandler,
 		authMiddleware, rateLimiter, cfg.UploadDir, cfg.FrontendDir,
 	)
 
@@ -111,6 +149,27 @@ func main() {
 	cleanupWorker := worker.NewCleanupWorker(notifService, cfg.CleanupInterval, 7*24*time.Hour)
 	cleanupWorker.Start()
 
+	schedulerWorker := worker.NewSchedulerWorker(postService, cfg.SchedulerInterval)
+	schedulerWorker.Start()
+
+	pollWorker := worker.NewPollWorker(pollService, cfg.SchedulerInterval)
+	pollWorker.Start()
+
+	previewWorker := worker.NewLinkPreviewWorker(previewService, previewQueue, cfg.SchedulerInterval)
+	previewWorker.Start()
+
+	storyWorker := worker.NewStoryCleanupWorker(storyService, cfg.CleanupInterval)
+	storyWorker.Start()
+
+	mutedWordWorker := worker.NewMutedWordCleanupWorker(mutedWordService, cfg.CleanupInterval)
+	mutedWordWorker.Start()
+
+	userMuteWorker := worker.NewUserMuteCleanupWorker(userMuteService, cfg.CleanupInterval)
+	userMuteWorker.Start()
+
+	suggestionWorker := worker.NewFriendSuggestionWorker(suggestionService, cfg.SchedulerInterval)
+	suggestionWorker.Start()
+
 	log.Printf("Server starting on port %s", cfg.ServerPort)
 	log.Fatal(http.ListenAndServe(":"+cfg.ServerPort, router.Setup()))
 }